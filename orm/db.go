@@ -27,6 +27,11 @@ var (
 		"in":          true,
 		"between":     true,
 		"isnull":      true,
+		"exists":      true,
+		"all":         true,
+		"nin":         true,
+		"size":        true,
+		"elemmatch":   true,
 		"regex":       true,
 	}
 )
@@ -150,6 +155,8 @@ func (d *dbBase) collectFieldValue(mi *modelInfo, fi *fieldInfo, ind reflect.Val
 						value = field.Float()
 					}
 				}
+			case TypeObjectIDField:
+				value = field.Interface()
 			case TypeTimeField, TypeDateField, TypeDateTimeField:
 				value = field.Interface()
 				if t, ok := value.(time.Time); ok {
@@ -211,18 +218,7 @@ func (d *dbBase) collectFieldValue(mi *modelInfo, fi *fieldInfo, ind reflect.Val
 						break
 					}
 				}
-				tnow := time.Now()
-				d.ins.TimeToDB(&tnow, tz)
-				value = tnow
-				if fi.isFielder {
-					f := field.Addr().Interface().(Fielder)
-					f.SetRaw(tnow.In(DefaultTimeLoc))
-				} else if field.Kind() == reflect.Ptr {
-					v := tnow.In(DefaultTimeLoc)
-					field.Set(reflect.ValueOf(&v))
-				} else {
-					field.Set(reflect.ValueOf(tnow.In(DefaultTimeLoc)))
-				}
+				value = d.setTimeNow(fi, field, tz)
 			}
 		case TypeJSONField, TypeJsonbField:
 			if s, ok := value.(string); (ok && len(s) == 0) || value == nil {
@@ -237,6 +233,81 @@ func (d *dbBase) collectFieldValue(mi *modelInfo, fi *fieldInfo, ind reflect.Val
 	return value, nil
 }
 
+// set now to the time field, return the time converted to db.
+func (d *dbBase) setTimeNow(fi *fieldInfo, field reflect.Value, tz *time.Location) time.Time {
+	tnow := time.Now()
+	d.ins.TimeToDB(&tnow, tz)
+	if fi.isFielder {
+		f := field.Addr().Interface().(Fielder)
+		f.SetRaw(tnow.In(DefaultTimeLoc))
+	} else if field.Kind() == reflect.Ptr {
+		v := tnow.In(DefaultTimeLoc)
+		field.Set(reflect.ValueOf(&v))
+	} else {
+		field.Set(reflect.ValueOf(tnow.In(DefaultTimeLoc)))
+	}
+	return tnow
+}
+
+// set auto_now, auto_now_add and default values to struct before writing.
+// on insert, auto_now/auto_now_add time fields and default fields are set only if they are zero.
+// on update, auto_now time fields are always set to now.
+func (d *dbBase) setAutoValues(mi *modelInfo, ind reflect.Value, insert bool, tz *time.Location) {
+	for _, fi := range mi.fields.fieldsDB {
+		if fi.pk || !fi.inModel {
+			continue
+		}
+		field := ind.FieldByIndex(fi.fieldIndex)
+		switch fi.fieldType {
+		case TypeTimeField, TypeDateField, TypeDateTimeField:
+			if insert && (fi.autoNow || fi.autoNowAdd) && isZeroField(field) || !insert && fi.autoNow {
+				d.setTimeNow(fi, field, tz)
+			}
+		default:
+			if insert && fi.version && isZeroField(field) {
+				setVersion(fi, field, 1)
+			}
+			if insert && fi.colDefault && fi.initial.Exist() && isZeroField(field) {
+				if _, err := d.setFieldValue(fi, getDefaultValue(fi), field); err != nil {
+					panic(fmt.Errorf("default value: `%s` %s", fi.initial, err.Error()))
+				}
+			}
+		}
+	}
+}
+
+// get the default value of field in the type of database value.
+func getDefaultValue(fi *fieldInfo) (value interface{}) {
+	v := fi.initial
+	switch {
+	case fi.fieldType == TypeBooleanField:
+		value, _ = v.Bool()
+	case fi.fieldType == TypeFloatField || fi.fieldType == TypeDecimalField:
+		value, _ = v.Float64()
+	case fi.fieldType&IsPositiveIntegerField > 0:
+		value, _ = v.Uint64()
+	case fi.fieldType&IsIntegerField > 0:
+		value, _ = v.Int64()
+	default:
+		value = v.String()
+	}
+	return
+}
+
+// check the struct field is zero value, nil or zero time.
+func isZeroField(field reflect.Value) bool {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return true
+		}
+		field = field.Elem()
+	}
+	if t, ok := field.Interface().(time.Time); ok {
+		return t.IsZero()
+	}
+	return field.IsZero()
+}
+
 // set values to struct column.
 func (d *dbBase) setColsValues(mi *modelInfo, ind *reflect.Value, cols []string, values []interface{}, tz *time.Location) {
 	for i, column := range cols {
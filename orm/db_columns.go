@@ -0,0 +1,211 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var (
+	ErrUnknownField = errors.New("<QuerySeter> unknown field")
+)
+
+// map the struct field names of QuerySeter to bson columns,
+// for filters, sort, projection, Distinct and update keys.
+// in strict mode the first unknown field is kept in err.
+type columnMapper struct {
+	mi     *modelInfo
+	scope  int
+	strict bool
+	prefix string // the array path of elemmatch
+	err    error
+}
+
+// create the column mapper of QuerySeter.
+func newColumnMapper(qs *querySet, mi *modelInfo) *columnMapper {
+	m := &columnMapper{mi: mi}
+	if qs != nil {
+		m.scope = qs.scope
+		m.strict = qs.strict
+	}
+	return m
+}
+
+// map the field name or path to column, the unknown names are kept.
+func (m *columnMapper) column(name string) string {
+	if m.prefix != "" {
+		return m.elemColumn(name)
+	}
+	col, ok := getColumn(m.mi, name)
+	if !ok && m.strict && m.err == nil {
+		m.err = fmt.Errorf("%w `%s` of model `%s`", ErrUnknownField, name, m.mi.fullName)
+	}
+	return col
+}
+
+// map the field name of array elements, the column is relative to the array.
+func (m *columnMapper) elemColumn(name string) string {
+	if m.mi == nil {
+		col, _ := getColumn(nil, name)
+		return col
+	}
+	col, ok := getColumn(m.mi, m.prefix+"."+name)
+	base, _ := getColumn(m.mi, m.prefix)
+	if ok && strings.HasPrefix(col, base+".") {
+		return col[len(base)+1:]
+	}
+	if m.strict && m.err == nil {
+		m.err = fmt.Errorf("%w `%s.%s` of model `%s`", ErrUnknownField, m.prefix, name, m.mi.fullName)
+	}
+	return name
+}
+
+// convert the condition to filter with the soft delete scope.
+func (m *columnMapper) filter(cond *Condition) bson.M {
+	return addSoftDeleteFilter(m.mi, m.condition(cond), m.scope)
+}
+
+// get the sort document of orders, the name with prefix `-` is descending.
+// the keys keep the order of orders.
+func (m *columnMapper) sort(orders []string) (r bson.D) {
+	r = make(bson.D, 0, len(orders))
+	for _, order := range orders {
+		if order == "" {
+			continue
+		}
+		if order[0] == '-' {
+			r = append(r, bson.E{Key: m.column(order[1:]), Value: -1})
+		} else {
+			r = append(r, bson.E{Key: m.column(order), Value: 1})
+		}
+	}
+	return
+}
+
+// get the projection document of cols.
+func (m *columnMapper) projection(cols []string) (r bson.M) {
+	r = bson.M{}
+	for _, col := range cols {
+		r[m.column(col)] = 1
+	}
+	return
+}
+
+// map the field keys of the operator documents of update.
+func (m *columnMapper) updateKeys(update bson.M) {
+	for op, v := range update {
+		doc, ok := v.(bson.M)
+		if !ok {
+			continue
+		}
+		mapped := make(bson.M, len(doc))
+		for k, val := range doc {
+			mapped[m.column(k)] = val
+		}
+		update[op] = mapped
+	}
+}
+
+// convert the condition of QuerySeter to filter with its soft delete scope.
+func getFilter(qs *querySet, mi *modelInfo, cond *Condition) (bson.M, error) {
+	m := newColumnMapper(qs, mi)
+	filter := m.filter(cond)
+	return filter, m.err
+}
+
+// map the field name or path to bson column.
+// the path is separated by `__` or `.`, e.g. L2__UserName is l2.username,
+// the names of nested structs are mapped by their bson tags, array indexes and positional operators are kept.
+// ok is false if a name of path is unknown, then the name is returned as it is.
+func getColumn(mi *modelInfo, name string) (column string, ok bool) {
+	path := strings.Split(strings.Replace(name, ExprSep, ".", -1), ".")
+	if mi == nil || strings.HasPrefix(path[0], "$") {
+		return strings.Join(path, "."), true
+	}
+	fi, ok := mi.fields.GetByAny(path[0])
+	if !ok {
+		return name, false
+	}
+	path[0] = fi.column
+	typ := fi.sf.Type
+	for i := 1; i < len(path); i++ {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		switch typ.Kind() {
+		case reflect.Slice, reflect.Array:
+			typ = typ.Elem()
+			if isArrayPath(path[i]) {
+				continue
+			}
+			// the field of array elements
+			i--
+			continue
+		case reflect.Struct:
+			col, ft, found := getStructColumn(typ, path[i])
+			if !found {
+				return name, false
+			}
+			path[i] = col
+			typ = ft
+		case reflect.Map, reflect.Interface:
+			// the keys of document are not checked
+			return strings.Join(path, "."), true
+		default:
+			return name, false
+		}
+	}
+	return strings.Join(path, "."), true
+}
+
+// check the name is array index or positional operator, like 0, $, $[] and $[elem].
+func isArrayPath(name string) bool {
+	if strings.HasPrefix(name, "$") {
+		return true
+	}
+	_, err := StrTo(name).Int()
+	return err == nil
+}
+
+// find the field of struct by name or bson key, the inline structs are searched too.
+func getStructColumn(typ reflect.Type, name string) (column string, ft reflect.Type, ok bool) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue
+		}
+		tags := strings.Split(sf.Tag.Get("bson"), ",")
+		key := tags[0]
+		if key == "-" {
+			continue
+		}
+		inline := false
+		for _, t := range tags[1:] {
+			if t == "inline" {
+				inline = true
+			}
+		}
+		if inline {
+			it := sf.Type
+			if it.Kind() == reflect.Ptr {
+				it = it.Elem()
+			}
+			if it.Kind() == reflect.Struct {
+				if column, ft, ok = getStructColumn(it, name); ok {
+					return
+				}
+			}
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(sf.Name)
+		}
+		if sf.Name == name || key == name {
+			return key, sf.Type, true
+		}
+	}
+	return
+}
@@ -0,0 +1,141 @@
+package orm
+
+import (
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DefaultCounterCollection the collection stores the auto increment counters
+var DefaultCounterCollection = "counters"
+
+// AutoIncr define the auto increment options of integer pk.
+// set it by the model method TableAutoIncr, e.g.
+//
+//	func (u *User) TableAutoIncr() orm.AutoIncr {
+//		return orm.AutoIncr{Start: 10000, Block: 100}
+//	}
+type AutoIncr struct {
+	Collection string // counters collection, default is DefaultCounterCollection
+	Key        string // _id of the counter document, default is the table name
+	Start      int64  // the first id, default is 1
+	Step       int64  // default is 1
+	Block      int64  // ids allocated from database once and cached in memory, default is 1
+}
+
+var (
+	counterCache = &_counterCache{cache: make(map[string]*counter)}
+)
+
+// counters collection cacher.
+type _counterCache struct {
+	mux   sync.Mutex
+	cache map[string]*counter
+}
+
+// get or create the counter of model in the database.
+func (cc *_counterCache) get(db *mongo.Database, mi *modelInfo) *counter {
+	ai := mi.autoIncr
+	name := db.Name() + "." + ai.Collection + "." + ai.Key
+	cc.mux.Lock()
+	defer cc.mux.Unlock()
+	c, ok := cc.cache[name]
+	if !ok {
+		c = &counter{ai: ai, next: 1}
+		cc.cache[name] = c
+	}
+	return c
+}
+
+// one auto increment counter.
+// the counter document stores the count of allocated ids in field seq.
+type counter struct {
+	sync.Mutex
+	ai   AutoIncr
+	next int64 // next cached seq
+	end  int64 // last cached seq, no cached seq if next > end
+}
+
+// allocate n seqs with $inc, at least one block.
+func (c *counter) alloc(col *mongo.Collection, n int64) (err error) {
+	if n < c.ai.Block {
+		n = c.ai.Block
+	}
+	opt := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	res := struct {
+		Seq int64 `bson:"seq"`
+	}{}
+	err = col.FindOneAndUpdate(todo, bson.M{"_id": c.ai.Key}, bson.M{"$inc": bson.M{"seq": n}}, opt).Decode(&res)
+	if err != nil {
+		return
+	}
+	c.next = res.Seq - n + 1
+	c.end = res.Seq
+	return
+}
+
+// get n ids, the cached seqs are used first.
+func (c *counter) ids(col *mongo.Collection, n int) (ids []int64, err error) {
+	c.Lock()
+	defer c.Unlock()
+	ids = make([]int64, 0, n)
+	for len(ids) < n {
+		if c.next > c.end {
+			if err = c.alloc(col, int64(n-len(ids))); err != nil {
+				return nil, err
+			}
+		}
+		ids = append(ids, c.ai.Start+(c.next-1)*c.ai.Step)
+		c.next++
+	}
+	return
+}
+
+// get the auto increment options from model, with the defaults.
+func getAutoIncr(mi *modelInfo, val reflect.Value) (ai AutoIncr) {
+	if fun := val.MethodByName("TableAutoIncr"); fun.IsValid() {
+		vals := fun.Call([]reflect.Value{})
+		if len(vals) > 0 && vals[0].CanInterface() {
+			if d, ok := vals[0].Interface().(AutoIncr); ok {
+				ai = d
+			}
+		}
+	}
+	if ai.Collection == "" {
+		ai.Collection = DefaultCounterCollection
+	}
+	if ai.Key == "" {
+		ai.Key = mi.table
+	}
+	if ai.Start == 0 {
+		ai.Start = 1
+	}
+	if ai.Step == 0 {
+		ai.Step = 1
+	}
+	if ai.Block <= 0 {
+		ai.Block = 1
+	}
+	return
+}
+
+// set the auto increment ids to the empty integer pks.
+func setAutoPks(db *mongo.Database, mi *modelInfo, inds []reflect.Value) (err error) {
+	ids, err := counterCache.get(db, mi).ids(db.Collection(mi.autoIncr.Collection), len(inds))
+	if err != nil {
+		return
+	}
+	fi := mi.fields.pk
+	for i, ind := range inds {
+		field := ind.FieldByIndex(fi.fieldIndex)
+		if fi.fieldType&IsPositiveIntegerField > 0 {
+			field.SetUint(uint64(ids[i]))
+		} else {
+			field.SetInt(ids[i])
+		}
+	}
+	return
+}
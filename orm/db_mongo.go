@@ -2,7 +2,9 @@ package orm
 
 import (
 	"errors"
+	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 	"time"
 
@@ -15,6 +17,12 @@ import (
 var (
 	// ErrMissPK missing pk error
 	ErrMissPK = errors.New("missed pk value")
+	// ErrInsertMultiFailed some documents failed in InsertMulti
+	ErrInsertMultiFailed = errors.New("<Ormer.InsertMulti> some documents failed to insert")
+	// ErrPipelineUpdate the update can not be converted to aggregation pipeline
+	ErrPipelineUpdate = errors.New("<Ormer> ColValue(ColExcept) can only be used with $set, $setOnInsert, $inc, $mul and $unset")
+	// ErrStaleObject the version of model is changed by others or the document is deleted
+	ErrStaleObject = errors.New("<Ormer> stale object, the document version is changed")
 )
 
 type OperatorUpdate string
@@ -23,6 +31,7 @@ var (
 	MgoSet         OperatorUpdate = "$set"
 	MgoUnSet       OperatorUpdate = "$unset"
 	MgoInc         OperatorUpdate = "$inc"
+	MgoMul         OperatorUpdate = "$mul"
 	MgoPush        OperatorUpdate = "$push"
 	MgoPushAll     OperatorUpdate = "$pushAll"
 	MgoAddToSet    OperatorUpdate = "$addToSet"
@@ -51,24 +60,24 @@ func newdbBaseMongo() dbBaser {
 func (d *dbBaseMongo) FindOne(qs *querySet, mi *modelInfo, cond *Condition, container interface{}, tz *time.Location, cols []string) (err error) {
 	db := qs.orm.db.(*DB).MDB
 	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
 	opt := options.FindOne()
 	if len(cols) > 0 {
-		projection := bson.M{}
-		for _, col := range cols {
-			projection[col] = 1
-		}
-		opt.SetProjection(projection)
+		opt.SetProjection(cm.projection(cols))
 	}
 
 	if len(qs.orders) > 0 {
-		opt.SetSort(getSort(qs.orders))
+		opt.SetSort(cm.sort(qs.orders))
 	}
 
 	if qs.offset != 0 {
 		opt.SetSkip(qs.offset)
 	}
 
-	filter := convertCondition(cond)
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
 
 	if qs != nil && qs.forContext {
 		err = col.FindOne(qs.ctx, filter, opt).Decode(container)
@@ -83,9 +92,14 @@ func (d *dbBaseMongo) FindOne(qs *querySet, mi *modelInfo, cond *Condition, cont
 func (d *dbBaseMongo) Distinct(qs *querySet, mi *modelInfo, cond *Condition, tz *time.Location, field string) (res []interface{}, err error) {
 	db := qs.orm.db.(*DB).MDB
 	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
 	opt := options.Distinct()
 
-	filter := convertCondition(cond)
+	field = cm.column(field)
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
 
 	if qs != nil && qs.forContext {
 		return col.Distinct(qs.ctx, field, filter, opt)
@@ -98,18 +112,15 @@ func (d *dbBaseMongo) Distinct(qs *querySet, mi *modelInfo, cond *Condition, tz
 func (d *dbBaseMongo) Find(qs *querySet, mi *modelInfo, cond *Condition, container interface{}, tz *time.Location, cols []string) (err error) {
 	db := qs.orm.db.(*DB).MDB
 	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
 
 	opt := options.Find()
 	if len(cols) > 0 {
-		projection := bson.M{}
-		for _, col := range cols {
-			projection[col] = 1
-		}
-		opt.SetProjection(projection)
+		opt.SetProjection(cm.projection(cols))
 	}
 
 	if len(qs.orders) > 0 {
-		opt.SetSort(getSort(qs.orders))
+		opt.SetSort(cm.sort(qs.orders))
 	}
 
 	if qs.limit != 0 {
@@ -120,7 +131,10 @@ func (d *dbBaseMongo) Find(qs *querySet, mi *modelInfo, cond *Condition, contain
 		opt.SetSkip(qs.offset)
 	}
 
-	filter := convertCondition(cond)
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
 	cur := &mongo.Cursor{}
 	if qs != nil && qs.forContext {
 		// Do something with content
@@ -145,7 +159,10 @@ func (d *dbBaseMongo) Count(qs *querySet, mi *modelInfo, cond *Condition, tz *ti
 
 	opt := options.Count()
 
-	filter := convertCondition(cond)
+	filter, err := getFilter(qs, mi, cond)
+	if err != nil {
+		return
+	}
 
 	if qs != nil && qs.forContext {
 		if len(filter) == 0 {
@@ -169,34 +186,238 @@ func (d *dbBaseMongo) Count(qs *querySet, mi *modelInfo, cond *Condition, tz *ti
 func (d *dbBaseMongo) UpdateMany(qs *querySet, mi *modelInfo, cond *Condition, operator OperatorUpdate, params Params, tz *time.Location) (i int64, err error) {
 	db := qs.orm.db.(*DB).MDB
 	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
 
 	opt := options.Update()
 
-	filter := convertCondition(cond)
-	update := bson.M{}
-	for col, val := range params {
-		// if fi, ok := mi.fields.GetByAny(col); !ok || !fi.dbcol {
-		// 	panic(fmt.Errorf("wrong field/column name `%s`", col))
-		// } else {
-		update[col] = val
-		// }
+	if err = validateParams(mi, operator, params); err != nil {
+		return
 	}
-	update = bson.M{
-		string(operator): update,
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
+	update := getUpdate(operator, params)
+	cm.updateKeys(update)
+	if err = cm.err; err != nil {
+		return
+	}
+	d.setAutoUpdate(mi, update, false, tz)
+	doc, err := getUpdateDoc(update)
+	if err != nil {
+		return
+	}
+	if af, ok := getArrayFilters(qs, mi); ok {
+		opt.SetArrayFilters(af)
+	}
+	r := &mongo.UpdateResult{}
+	if qs != nil && qs.forContext {
+		r, err = col.UpdateMany(qs.ctx, filter, doc, opt)
+	} else {
+		// Do something without content
+		r, err = col.UpdateMany(todo, filter, doc, opt)
+	}
+	if err != nil {
+		return
+	}
+
+	i = r.ModifiedCount
+
+	return
+}
+
+// update the recodes with the update builder, only the first matched record if one is true.
+func (d *dbBaseMongo) UpdateWith(qs *querySet, mi *modelInfo, cond *Condition, u *Update, one bool, tz *time.Location) (res *UpdateResult, err error) {
+	db := qs.orm.db.(*DB).MDB
+	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
+
+	update := u.toBSON(cm)
+	for _, op := range []OperatorUpdate{MgoSet, MgoSetOnInsert} {
+		if m, ok := update[string(op)].(bson.M); ok {
+			if err = validateParams(mi, op, Params(m)); err != nil {
+				return
+			}
+		}
+	}
+
+	// the fields of model structs
+	vfi := mi.fields.version
+	var vfields []reflect.Value
+	var vers []int64
+	for _, p := range u.params {
+		if p.md == nil {
+			continue
+		}
+		val := reflect.ValueOf(p.md)
+		if val.Type() != mi.addrField.Type() {
+			return nil, fmt.Errorf("<Update.SetModel> model must be `*%s`, but found `%s`", mi.fullName, val.Type())
+		}
+		ind := val.Elem()
+		if vfi != nil {
+			// the version of model is checked like Ormer.Update, only one document can match it
+			if !one {
+				return nil, fmt.Errorf("<Update.SetModel> model `%s` has version field, use UpdateOne instead of UpdateMany", mi.fullName)
+			}
+			vfield := ind.FieldByIndex(vfi.fieldIndex)
+			vfields = append(vfields, vfield)
+			vers = append(vers, getVersion(vfi, vfield))
+		}
+		cols := p.cols
+		if len(cols) == 0 {
+			for _, fi := range mi.fields.fieldsDB {
+				if !fi.pk && !fi.version {
+					cols = append(cols, fi.column)
+				}
+			}
+		}
+		if err = validateModel(mi, ind, cols); err != nil {
+			return
+		}
+		names := make([]string, 0, len(cols))
+		args, _, e := d.collectValues(mi, ind, cols, false, false, &names, tz)
+		if e != nil {
+			return nil, e
+		}
+		set, ok := update[string(MgoSet)].(bson.M)
+		if !ok {
+			set = bson.M{}
+			update[string(MgoSet)] = set
+		}
+		for i, name := range names {
+			if fi := mi.fields.GetByColumn(name); fi != nil && (fi.pk || fi.version) {
+				continue
+			}
+			set[name] = args[i]
+		}
+	}
+	if len(update) == 0 {
+		return nil, ErrArgs
+	}
+
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
+	for _, ver := range vers {
+		filter = addVersionFilter(mi, filter, ver)
+	}
+	d.setAutoUpdate(mi, update, u.upsert, tz)
+	if u.upsert {
+		if err = setUpsertPk(db, mi, filter, update); err != nil {
+			return
+		}
+	}
+
+	doc, err := getUpdateDoc(update)
+	if err != nil {
+		return
+	}
+
+	opt := options.Update().SetUpsert(u.upsert)
+	if af, ok := getArrayFilters(qs, mi); ok {
+		opt.SetArrayFilters(af)
+	}
+	ctx := todo
+	if qs != nil && qs.forContext {
+		ctx = qs.ctx
+	}
+	r := &mongo.UpdateResult{}
+	if one {
+		r, err = col.UpdateOne(ctx, filter, doc, opt)
+	} else {
+		r, err = col.UpdateMany(ctx, filter, doc, opt)
+	}
+	if len(vers) > 0 {
+		if err == nil && r.MatchedCount == 0 && r.UpsertedCount == 0 || isDuplicatePk(err) {
+			return nil, ErrStaleObject
+		}
+		if err == nil {
+			for i, vfield := range vfields {
+				setVersion(vfi, vfield, vers[i]+1)
+			}
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	res = &UpdateResult{
+		MatchedCount:  r.MatchedCount,
+		ModifiedCount: r.ModifiedCount,
+		UpsertedCount: r.UpsertedCount,
+		UpsertedID:    r.UpsertedID,
+	}
+	return
+}
+
+// update the recodes with aggregation pipeline.
+func (d *dbBaseMongo) UpdatePipeline(qs *querySet, mi *modelInfo, cond *Condition, stages []interface{}, tz *time.Location) (i int64, err error) {
+	db := qs.orm.db.(*DB).MDB
+	col := db.Collection(mi.table)
+
+	if len(stages) == 0 {
+		return 0, ErrArgs
 	}
+	filter, err := getFilter(qs, mi, cond)
+	if err != nil {
+		return
+	}
+	pipeline := d.getAutoPipeline(mi, stages, tz)
+
 	r := &mongo.UpdateResult{}
 	if qs != nil && qs.forContext {
-		r, err = col.UpdateMany(qs.ctx, filter, update, opt)
+		r, err = col.UpdateMany(qs.ctx, filter, pipeline, options.Update())
 	} else {
 		// Do something without content
-		r, err = col.UpdateMany(todo, filter, update, opt)
+		r, err = col.UpdateMany(todo, filter, pipeline, options.Update())
 	}
 	if err != nil {
 		return
 	}
 
 	i = r.ModifiedCount
+	return
+}
+
+// update one record by pk with aggregation pipeline.
+func (d *dbBaseMongo) UpdatePipelineOne(q dbQuerier, mi *modelInfo, ind reflect.Value, tz *time.Location, stages []interface{}) (i int64, err error) {
+	db := q.(*DB).MDB
+	col := db.Collection(mi.table)
+
+	c, val, b := getExistPk(mi, ind)
+	if !b {
+		return 0, ErrHaveNoPK
+	}
+	if len(stages) == 0 {
+		return 0, ErrArgs
+	}
+
+	filter := bson.M{
+		c: val,
+	}
+	vfi := mi.fields.version
+	var vfield reflect.Value
+	var ver int64
+	if vfi != nil {
+		vfield = ind.FieldByIndex(vfi.fieldIndex)
+		ver = getVersion(vfi, vfield)
+		filter = addVersionFilter(mi, filter, ver)
+	}
+	pipeline := d.getAutoPipeline(mi, stages, tz)
 
+	// Do something without content
+	data, err := col.UpdateOne(todo, filter, pipeline, options.Update())
+	if err != nil {
+		return
+	}
+	if vfi != nil {
+		if data.MatchedCount == 0 {
+			return 0, ErrStaleObject
+		}
+		setVersion(vfi, vfield, ver+1)
+	}
+	i = data.ModifiedCount
 	return
 }
 
@@ -207,7 +428,10 @@ func (d *dbBaseMongo) DeleteMany(qs *querySet, mi *modelInfo, cond *Condition, t
 
 	opt := options.Delete()
 
-	filter := convertCondition(cond)
+	filter, err := getFilter(qs, mi, cond)
+	if err != nil {
+		return
+	}
 
 	r := &mongo.DeleteResult{}
 	if qs != nil && qs.forContext {
@@ -225,6 +449,161 @@ func (d *dbBaseMongo) DeleteMany(qs *querySet, mi *modelInfo, cond *Condition, t
 	return
 }
 
+// find one record and update it.
+func (d *dbBaseMongo) FindOneAndUpdate(qs *querySet, mi *modelInfo, cond *Condition, container interface{}, operator OperatorUpdate, params Params, fo FindOneAndOptions, tz *time.Location) (err error) {
+	db := qs.orm.db.(*DB).MDB
+	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
+
+	opt := options.FindOneAndUpdate()
+	if len(fo.Cols) > 0 {
+		opt.SetProjection(cm.projection(fo.Cols))
+	}
+
+	if len(qs.orders) > 0 {
+		opt.SetSort(cm.sort(qs.orders))
+	}
+
+	opt.SetUpsert(fo.Upsert)
+	if fo.ReturnNew {
+		opt.SetReturnDocument(options.After)
+	}
+
+	if err = validateParams(mi, operator, params); err != nil {
+		return
+	}
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
+	update := getUpdate(operator, params)
+	cm.updateKeys(update)
+	if err = cm.err; err != nil {
+		return
+	}
+	d.setAutoUpdate(mi, update, fo.Upsert, tz)
+	if fo.Upsert {
+		if err = setUpsertPk(db, mi, filter, update); err != nil {
+			return
+		}
+	}
+	doc, err := getUpdateDoc(update)
+	if err != nil {
+		return
+	}
+	if af, ok := getArrayFilters(qs, mi); ok {
+		opt.SetArrayFilters(af)
+	}
+
+	if qs != nil && qs.forContext {
+		err = col.FindOneAndUpdate(qs.ctx, filter, doc, opt).Decode(container)
+	} else {
+		err = col.FindOneAndUpdate(todo, filter, doc, opt).Decode(container)
+	}
+
+	return
+}
+
+// find one record and replace it.
+func (d *dbBaseMongo) FindOneAndReplace(qs *querySet, mi *modelInfo, cond *Condition, container interface{}, replacement interface{}, fo FindOneAndOptions, tz *time.Location) (err error) {
+	db := qs.orm.db.(*DB).MDB
+	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
+
+	opt := options.FindOneAndReplace()
+	if len(fo.Cols) > 0 {
+		opt.SetProjection(cm.projection(fo.Cols))
+	}
+
+	if len(qs.orders) > 0 {
+		opt.SetSort(cm.sort(qs.orders))
+	}
+
+	opt.SetUpsert(fo.Upsert)
+	if fo.ReturnNew {
+		opt.SetReturnDocument(options.After)
+	}
+
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
+	// set auto values, and generate the pk for upsert when replacement is a model struct
+	if val := reflect.ValueOf(replacement); val.IsValid() && val.Type() == mi.addrField.Type() {
+		ind := val.Elem()
+		_, _, ok := getExistPk(mi, ind)
+		d.setAutoValues(mi, ind, !ok && fo.Upsert, tz)
+		if err = validateModel(mi, ind, nil); err != nil {
+			return
+		}
+		if fo.Upsert {
+			if err = setNewPk(db, mi, ind); err != nil {
+				return
+			}
+		}
+		if ok {
+			var restore func(error) error
+			if filter, restore = replaceVersion(mi, ind, filter); restore != nil {
+				defer func() {
+					if err != nil {
+						err = restore(err)
+					}
+				}()
+			}
+		}
+	}
+
+	if qs != nil && qs.forContext {
+		err = col.FindOneAndReplace(qs.ctx, filter, replacement, opt).Decode(container)
+	} else {
+		err = col.FindOneAndReplace(todo, filter, replacement, opt).Decode(container)
+	}
+
+	return
+}
+
+// find one record and delete it.
+func (d *dbBaseMongo) FindOneAndDelete(qs *querySet, mi *modelInfo, cond *Condition, container interface{}, tz *time.Location, cols []string) (err error) {
+	db := qs.orm.db.(*DB).MDB
+	col := db.Collection(mi.table)
+	cm := newColumnMapper(qs, mi)
+
+	opt := options.FindOneAndDelete()
+	if len(cols) > 0 {
+		opt.SetProjection(cm.projection(cols))
+	}
+
+	if len(qs.orders) > 0 {
+		opt.SetSort(cm.sort(qs.orders))
+	}
+
+	filter := cm.filter(cond)
+	if err = cm.err; err != nil {
+		return
+	}
+
+	// the document of model with soft_delete field is marked as deleted unless Unscoped
+	if fi := mi.fields.softDelete; fi != nil && cm.scope != scopeUnscoped {
+		update, _ := d.getSoftDeleteUpdate(fi, tz, false)
+		uopt := options.FindOneAndUpdate().SetReturnDocument(options.After)
+		if opt.Projection != nil {
+			uopt.SetProjection(opt.Projection)
+		}
+		if opt.Sort != nil {
+			uopt.SetSort(opt.Sort)
+		}
+		return col.FindOneAndUpdate(qs.getContext(), filter, update, uopt).Decode(container)
+	}
+
+	if qs != nil && qs.forContext {
+		err = col.FindOneAndDelete(qs.ctx, filter, opt).Decode(container)
+	} else {
+		err = col.FindOneAndDelete(todo, filter, opt).Decode(container)
+	}
+
+	return
+}
+
 // get indexview.
 func (d *dbBaseMongo) Indexes(qs *querySet, mi *modelInfo, tz *time.Location) (iv IndexViewer) {
 	db := qs.orm.db.(*DB).MDB
@@ -262,6 +641,7 @@ func (d *dbBaseMongo) Read(q dbQuerier, mi *modelInfo, ind reflect.Value, contai
 	for i, p := range whereCols {
 		filter[p] = args[i]
 	}
+	filter = addSoftDeleteFilter(mi, filter, scopeDefault)
 	// Do something without content
 	data, err := col.FindOne(todo, filter, opt).DecodeBytes()
 	if err != nil {
@@ -276,11 +656,12 @@ func (d *dbBaseMongo) Read(q dbQuerier, mi *modelInfo, ind reflect.Value, contai
 func (d *dbBaseMongo) InsertOne(q dbQuerier, mi *modelInfo, ind reflect.Value, container interface{}, tz *time.Location) (id interface{}, err error) {
 	db := q.(*DB).MDB
 	col := db.Collection(mi.table)
-	_, _, b := getExistPk(mi, ind)
-	name := mi.fields.pk.name
-
-	if !b {
-		reflect.ValueOf(container).Elem().FieldByName(name).SetString(primitive.NewObjectID().Hex())
+	d.setAutoValues(mi, ind, true, tz)
+	if err = validateModel(mi, ind, nil); err != nil {
+		return
+	}
+	if err = setNewPk(db, mi, ind); err != nil {
+		return
 	}
 
 	opt := options.InsertOne()
@@ -294,37 +675,94 @@ func (d *dbBaseMongo) InsertOne(q dbQuerier, mi *modelInfo, ind reflect.Value, c
 	return
 }
 
-// insert all records.
-func (d *dbBaseMongo) InsertMany(q dbQuerier, mi *modelInfo, ind reflect.Value, containers interface{}, tz *time.Location) (ids interface{}, err error) {
+// insert all records chunk by chunk.
+func (d *dbBaseMongo) InsertMany(q dbQuerier, mi *modelInfo, inds []reflect.Value, io InsertMultiOptions, tz *time.Location) (res *InsertMultiResult, err error) {
 	db := q.(*DB).MDB
 	col := db.Collection(mi.table)
-	_, _, b := getExistPk(mi, ind)
-	name := mi.fields.pk.name
-	sind := reflect.Indirect(reflect.ValueOf(containers))
 
-	cs := []interface{}{}
+	size := io.ChunkSize
+	if size <= 0 {
+		size = DefaultInsertChunkSize
+	}
+
+	opt := options.InsertMany().SetOrdered(!io.Unordered)
 
-	if !b {
-		for i := 0; i < sind.Len(); i++ {
-			c := reflect.Indirect(sind.Index(i))
-			c.FieldByName(name).SetString(primitive.NewObjectID().Hex())
-			cs = append(cs, c.Interface())
+	res = &InsertMultiResult{Ids: make([]interface{}, len(inds))}
+	// nothing is inserted if any model is invalid
+	for i, ind := range inds {
+		d.setAutoValues(mi, ind, true, tz)
+		if e := validateModel(mi, ind, nil); e != nil {
+			res.Failed = append(res.Failed, InsertFailure{Index: i, Err: e})
 		}
 	}
+	if len(res.Failed) > 0 {
+		return res, ErrInsertMultiFailed
+	}
 
-	opt := options.InsertMany()
+	for start := 0; start < len(inds); start += size {
+		end := start + size
+		if end > len(inds) {
+			end = len(inds)
+		}
 
-	// Do something without content
-	data, err := col.InsertMany(todo, cs, opt)
-	if err != nil {
-		return
+		if err = setNewPks(db, mi, inds[start:end]); err != nil {
+			return
+		}
+		cs := make([]interface{}, 0, end-start)
+		for _, ind := range inds[start:end] {
+			cs = append(cs, ind.Addr().Interface())
+		}
+
+		// Do something without content
+		_, e := col.InsertMany(todo, cs, opt)
+
+		failed := map[int]bool{}
+		first := end - start
+		if e != nil {
+			we, ok := e.(mongo.BulkWriteException)
+			if !ok || len(we.WriteErrors) == 0 {
+				return res, e
+			}
+			for _, e := range we.WriteErrors {
+				failed[e.Index] = true
+				if e.Index < first {
+					first = e.Index
+				}
+				res.Failed = append(res.Failed, InsertFailure{Index: start + e.Index, Code: e.Code, Err: e})
+			}
+		}
+
+		for i := start; i < end; i++ {
+			if failed[i-start] || !io.Unordered && i-start > first {
+				continue
+			}
+			_, res.Ids[i], _ = getExistPk(mi, inds[i])
+			res.InsertedCount++
+		}
+
+		// ordered insert stops at the first failure
+		if e != nil && !io.Unordered {
+			break
+		}
+	}
+
+	if len(res.Failed) > 0 {
+		err = ErrInsertMultiFailed
 	}
-	ids = data.InsertedIDs
 	return
 }
 
 // update one record.
 func (d *dbBaseMongo) UpdateOne(q dbQuerier, mi *modelInfo, ind reflect.Value, container interface{}, tz *time.Location, cols []string) (id interface{}, err error) {
+	if len(cols) == 0 {
+		cols = mi.fields.dbcols
+	}
+	return d.UpdateChanged(q, mi, ind, container, tz, cols, nil, true)
+}
+
+// update the cols of one record and unset the columns in unsets.
+// the version is increased, it is checked and set back to model only if checkVersion.
+func (d *dbBaseMongo) UpdateChanged(q dbQuerier, mi *modelInfo, ind reflect.Value, container interface{}, tz *time.Location, cols []string, unsets []string, checkVersion bool) (id interface{}, err error) {
 	db := q.(*DB).MDB
 	col := db.Collection(mi.table)
 	c, val, b := getExistPk(mi, ind)
@@ -336,8 +774,12 @@ func (d *dbBaseMongo) UpdateOne(q dbQuerier, mi *modelInfo, ind reflect.Value, c
 	var whereCols []string
 	var args []interface{}
 
-	if len(cols) == 0 {
-		cols = mi.fields.dbcols
+	// auto_now fields are always updated
+	cols = appendAutoNowCols(mi, cols)
+	vcols := make([]string, 0, len(cols)+len(unsets))
+	vcols = append(append(vcols, cols...), unsets...)
+	if err = validateModel(mi, ind, vcols); err != nil {
+		return
 	}
 	whereCols = make([]string, 0, len(cols))
 	args, _, err = d.collectValues(mi, ind, cols, false, false, &whereCols, tz)
@@ -349,21 +791,108 @@ func (d *dbBaseMongo) UpdateOne(q dbQuerier, mi *modelInfo, ind reflect.Value, c
 		c: val,
 	}
 
-	update := bson.M{}
+	vfi := mi.fields.version
+	var vfield reflect.Value
+	var ver int64
+	if vfi != nil && checkVersion {
+		vfield = ind.FieldByIndex(vfi.fieldIndex)
+		ver = getVersion(vfi, vfield)
+		filter = addVersionFilter(mi, filter, ver)
+	}
+
+	set := bson.M{}
 	for i, p := range whereCols {
-		if p != c {
-			update[p] = args[i]
+		if p != c && (vfi == nil || p != vfi.column) {
+			set[p] = args[i]
 		}
 	}
 
-	update = bson.M{
-		"$set": update,
+	unset := bson.M{}
+	for _, p := range unsets {
+		if p != c && (vfi == nil || p != vfi.column) {
+			unset[p] = ""
+		}
+	}
+
+	update := bson.M{}
+	if len(set) > 0 {
+		update["$set"] = set
+	}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+	if vfi != nil {
+		update["$inc"] = bson.M{vfi.column: 1}
 	}
 
 	// Do something without content
 	data, err := col.UpdateOne(todo, filter, update, opt)
-	id = data.UpsertedID
-	return
+	if err != nil {
+		return
+	}
+	if vfi != nil && checkVersion {
+		if data.MatchedCount == 0 {
+			return nil, ErrStaleObject
+		}
+		setVersion(vfi, vfield, ver+1)
+	}
+	id = data.UpsertedID
+	return
+}
+
+// replace one record by pk with the whole struct.
+func (d *dbBaseMongo) ReplaceOne(q dbQuerier, mi *modelInfo, ind reflect.Value, container interface{}, tz *time.Location, opts []*options.ReplaceOptions) (id interface{}, err error) {
+	db := q.(*DB).MDB
+	col := db.Collection(mi.table)
+	_, _, b := getExistPk(mi, ind)
+	if !b {
+		// upsert with empty pk inserts a new document
+		ro := options.MergeReplaceOptions(opts...)
+		if ro.Upsert == nil || !*ro.Upsert {
+			return nil, ErrHaveNoPK
+		}
+		if err = setNewPk(db, mi, ind); err != nil {
+			return
+		}
+	}
+	d.setAutoValues(mi, ind, !b, tz)
+	if err = validateModel(mi, ind, nil); err != nil {
+		return
+	}
+	c, val, _ := getExistPk(mi, ind)
+
+	filter := bson.M{
+		c: val,
+	}
+
+	var restore func(error) error
+	if b {
+		filter, restore = replaceVersion(mi, ind, filter)
+	}
+
+	// Do something without content
+	data, err := col.ReplaceOne(todo, filter, container, opts...)
+	if restore != nil && (err != nil || data.MatchedCount == 0 && data.UpsertedCount == 0) {
+		err = restore(err)
+	}
+	if err != nil {
+		return
+	}
+	id = data.UpsertedID
+	return
+}
+
+// execute bulk write operations.
+func (d *dbBaseMongo) BulkWrite(q dbQuerier, mi *modelInfo, models []mongo.WriteModel, ordered bool, tz *time.Location, ops []string, ids []interface{}) (res *BulkResult, err error) {
+	db := q.(*DB).MDB
+	col := db.Collection(mi.table)
+
+	opt := options.BulkWrite().SetOrdered(ordered)
+
+	// Do something without content
+	r, err := col.BulkWrite(todo, models, opt)
+	res = newBulkResult(r, err, ordered, ops, ids)
+	return
 }
 
 // delete one record.
@@ -401,29 +930,35 @@ func (d *dbBaseMongo) DeleteOne(q dbQuerier, mi *modelInfo, ind reflect.Value, c
 	return
 }
 
-func convertCondition(cond *Condition) (filter bson.M) {
+// convert the condition to filter, the unknown fields are kept.
+func convertCondition(mi *modelInfo, cond *Condition) bson.M {
+	return (&columnMapper{mi: mi}).condition(cond)
+}
+
+// convert the condition to filter.
+// the expressions are grouped like SQL, AND binds tighter than OR,
+// so `a AND b OR c` is {$or: [{a, b}, {c}]}.
+func (m *columnMapper) condition(cond *Condition) (filter bson.M) {
+	mi := m.mi
+	if m.prefix != "" {
+		// the fields of array elements
+		mi = nil
+	}
 	filter = bson.M{}
 	if cond == nil {
 		return
 	}
-	for i, p := range cond.params {
+	var groups [][]bson.M
+	for _, p := range cond.params {
+		var term bson.M
 		if p.isCond {
-			f := convertCondition(p.cond)
-			if i > 0 {
-				if p.isOr {
-					filter = bson.M{
-						"$or": bson.A{filter, f},
-					}
-				} else {
-					// where += "AND "
-					filter = bson.M{
-						"$and": bson.A{filter, f},
-					}
-				}
-			} else {
-				filter = f
+			term = m.condition(p.cond)
+			if len(term) == 0 {
+				continue
+			}
+			if p.isNot {
+				term = bson.M{"$nor": bson.A{term}}
 			}
-
 		} else {
 			exprs := p.exprs
 
@@ -438,29 +973,206 @@ func convertCondition(cond *Condition) (filter bson.M) {
 				operator = "eq"
 			}
 
-			k, v := getCond(exprs, p.args, operator)
-
-			if i > 0 {
-				if p.isOr {
-					filter = bson.M{
-						"$or": bson.A{filter, bson.M{k: v}},
-					}
-				} else {
-					// where += "AND "
-					filter[k] = v
-				}
+			args := getFilterArgs(mi, exprs, p.args)
+			switch operator {
+			case "between", "nq", "ne":
+				args = getFilterValues(getFilterField(mi, exprs), args, DefaultTimeLoc)
+			}
+			var k string
+			if len(exprs) > 0 {
+				k = m.column(strings.Join(exprs, "."))
+			}
+			var v interface{}
+			if operator == "elemmatch" {
+				v = m.elemMatch(strings.Join(exprs, "."), p.args)
 			} else {
-				filter[k] = v
+				_, v = getCond([]string{k}, args, operator)
+			}
+			if p.isNot {
+				v = negateCond(v)
 			}
+			term = bson.M{k: v}
+		}
 
+		if p.isOr || len(groups) == 0 {
+			groups = append(groups, nil)
 		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], term)
+	}
 
+	switch len(groups) {
+	case 0:
+	case 1:
+		filter = mergeFilters(groups[0])
+	default:
+		or := make(bson.A, 0, len(groups))
+		for _, g := range groups {
+			or = append(or, mergeFilters(g))
+		}
+		filter = bson.M{"$or": or}
 	}
 	return
 }
 
+// compile the *Condition arg of elemmatch, the names are mapped by the element fields of path.
+// the expressions without field name, like And("gte", 80), match the elements themselves,
+// they can only be combined by AND with distinct operators, other forms panic.
+func (m *columnMapper) elemMatch(path string, args []interface{}) bson.M {
+	var cond *Condition
+	if len(args) > 0 {
+		cond, _ = args[0].(*Condition)
+	}
+	if cond == nil {
+		panic(fmt.Errorf("<Condition> operator `elemmatch` need a *Condition arg"))
+	}
+	sub := &columnMapper{mi: m.mi, strict: m.strict, prefix: path}
+	if m.prefix != "" {
+		sub.prefix = m.prefix + "." + path
+	}
+	match := sub.condition(cond)
+	if sub.err != nil && m.err == nil {
+		m.err = sub.err
+	}
+	// the operators of elements themselves must be merged to one document,
+	// e.g. And("gte", 80).And("lt", 90) is {$elemMatch: {$gte: 80, $lt: 90}}
+	if v, ok := match[""]; ok {
+		ops, isDoc := v.(bson.M)
+		if len(match) != 1 || !isDoc || !isOperatorDoc(ops) {
+			panic(fmt.Errorf("<Condition> elemmatch of `%s` can not mix the element values with fields", path))
+		}
+		match = ops
+	} else if hasFilterColumn(match, "") {
+		panic(fmt.Errorf("<Condition> elemmatch of `%s` only supports the element values with AND of distinct operators, use Or of elemmatch instead of Or in it", path))
+	}
+	return bson.M{"$elemMatch": match}
+}
+
+// merge the filters by AND.
+// the operators of same field are merged to one document, e.g. {age: {$gt: 1, $lt: 9}},
+// the filters can not be merged are added to $and.
+func mergeFilters(filters []bson.M) bson.M {
+	doc := bson.M{}
+	var and bson.A
+	for _, f := range filters {
+		merged, ok := mergeFilter(doc, f)
+		if !ok {
+			and = append(and, f)
+			continue
+		}
+		doc = merged
+	}
+	if len(and) == 0 {
+		return doc
+	}
+	if len(doc) > 0 {
+		and = append(bson.A{doc}, and...)
+	}
+	if len(and) == 1 {
+		return and[0].(bson.M)
+	}
+	return bson.M{"$and": and}
+}
+
+// merge f to doc, ok is false if a key conflicts.
+func mergeFilter(doc, f bson.M) (merged bson.M, ok bool) {
+	merged = make(bson.M, len(doc)+len(f))
+	for k, v := range doc {
+		merged[k] = v
+	}
+	for k, v := range f {
+		old, exist := merged[k]
+		if !exist {
+			merged[k] = v
+			continue
+		}
+		om, ok1 := old.(bson.M)
+		nm, ok2 := v.(bson.M)
+		if !ok1 || !ok2 || !isOperatorDoc(om) || !isOperatorDoc(nm) {
+			return nil, false
+		}
+		m := make(bson.M, len(om)+len(nm))
+		for op, arg := range om {
+			m[op] = arg
+		}
+		for op, arg := range nm {
+			if _, exist := m[op]; exist {
+				return nil, false
+			}
+			m[op] = arg
+		}
+		merged[k] = m
+	}
+	return merged, true
+}
+
+// check all keys of document are operators.
+func isOperatorDoc(m bson.M) bool {
+	if len(m) == 0 {
+		return false
+	}
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return false
+		}
+	}
+	return true
+}
+
 func getCond(params []string, args []interface{}, operator string) (k string, v interface{}) {
 	k = strings.Join(params, ".")
+	if pattern, options, ok := getStringLookup(operator); ok && len(args) > 0 {
+		v = bson.M{
+			"$regex": primitive.Regex{Pattern: fmt.Sprintf(pattern, regexp.QuoteMeta(ToStr(args[0]))), Options: options},
+		}
+		return
+	}
+	switch operator {
+	case "exact":
+		operator = "eq"
+	case "nq":
+		operator = "ne"
+	case "between":
+		if len(args) != 2 {
+			panic(fmt.Errorf("<Condition> operator `between` need 2 args not %d", len(args)))
+		}
+		v = bson.M{
+			"$gte": args[0],
+			"$lte": args[1],
+		}
+		return
+	case "isnull":
+		// null matches the null and missing fields
+		if len(args) > 0 && isTrueArg(args[0]) {
+			v = bson.M{"$eq": nil}
+		} else {
+			v = bson.M{"$ne": nil}
+		}
+		return
+	case "exists":
+		v = bson.M{"$exists": len(args) > 0 && isTrueArg(args[0])}
+		return
+	case "all", "nin":
+		// the args or one slice arg
+		var a interface{} = args
+		if len(args) == 1 {
+			a = args[0]
+			if val := reflect.ValueOf(a); val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+				a = bson.A{a}
+			}
+		}
+		v = bson.M{"$" + operator: a}
+		return
+	case "size":
+		if len(args) != 1 {
+			panic(fmt.Errorf("<Condition> operator `size` need 1 arg not %d", len(args)))
+		}
+		n, err := StrTo(ToStr(args[0])).Int()
+		if err != nil {
+			panic(fmt.Errorf("<Condition> operator `size` need a integer arg, %s", err))
+		}
+		v = bson.M{"$size": n}
+		return
+	}
 	if len(args) == 0 {
 		v = bson.M{}
 	} else if len(args) == 1 {
@@ -476,19 +1188,574 @@ func getCond(params []string, args []interface{}, operator string) (k string, v
 	return
 }
 
-func getSort(orders []string) (r bson.M) {
-	r = bson.M{}
-	if len(orders) == 0 {
-		return
+// negate the operator expression of field,
+// $eq, $ne, $in and $nin are swapped, others are wrapped by $not.
+func negateCond(v interface{}) interface{} {
+	m, ok := v.(bson.M)
+	if !ok || len(m) == 0 {
+		return v
 	}
-	for _, order := range orders {
-		if order[0] == '-' {
-			order = order[1:]
-			r[order] = -1
-		} else {
-			r[order] = 1
+	if len(m) == 1 {
+		for op, arg := range m {
+			switch op {
+			case "$eq":
+				return bson.M{"$ne": arg}
+			case "$ne":
+				return bson.M{"$eq": arg}
+			case "$in":
+				return bson.M{"$nin": arg}
+			case "$nin":
+				return bson.M{"$in": arg}
+			case "$regex":
+				// $not accepts the regex object, not the $regex operator
+				if pattern, ok := arg.(string); ok {
+					arg = primitive.Regex{Pattern: pattern}
+				}
+				return bson.M{"$not": arg}
+			}
+		}
+	}
+	return bson.M{"$not": m}
+}
+
+// get the $regex pattern and options of string lookup operator,
+// the pattern has a %s verb for the escaped value.
+func getStringLookup(operator string) (pattern, options string, ok bool) {
+	switch operator {
+	case "contains":
+		return "%s", "", true
+	case "icontains":
+		return "%s", "i", true
+	case "startswith":
+		return "^%s", "", true
+	case "istartswith":
+		return "^%s", "i", true
+	case "endswith":
+		return "%s$", "", true
+	case "iendswith":
+		return "%s$", "i", true
+	case "iexact":
+		return "^%s$", "i", true
+	}
+	return
+}
+
+// set new pks to the models which pk is empty.
+// the IDGenerator of model is used if it has,
+// otherwise ObjectID pk and string pk get a new ObjectID (or its hex),
+// auto integer pk gets the next ids of its counter.
+func setNewPks(db *mongo.Database, mi *modelInfo, inds []reflect.Value) error {
+	fi := mi.fields.pk
+	autos := make([]reflect.Value, 0, len(inds))
+	for _, ind := range inds {
+		if _, _, ok := getExistPk(mi, ind); ok {
+			continue
+		}
+		field := ind.FieldByIndex(fi.fieldIndex)
+		if mi.idGen != nil {
+			id, err := mi.idGen.NewID()
+			if err != nil {
+				return err
+			}
+			if err = setPkValue(fi, field, id); err != nil {
+				return err
+			}
+			continue
+		}
+		switch {
+		case fi.fieldType == TypeObjectIDField:
+			field.Set(reflect.ValueOf(primitive.NewObjectID()))
+		case fi.fieldType&IsIntegerField > 0:
+			if fi.auto {
+				autos = append(autos, ind)
+			}
+		case field.Kind() == reflect.String:
+			field.SetString(primitive.NewObjectID().Hex())
+		}
+	}
+	if len(autos) > 0 {
+		return setAutoPks(db, mi, autos)
+	}
+	return nil
+}
+
+// set new pk to the model if pk is empty.
+func setNewPk(db *mongo.Database, mi *modelInfo, ind reflect.Value) error {
+	return setNewPks(db, mi, []reflect.Value{ind})
+}
+
+// add auto_now values to $set of update for the columns not updated,
+// auto_now_add and default values are added to $setOnInsert for upsert.
+func (d *dbBaseMongo) setAutoUpdate(mi *modelInfo, update bson.M, upsert bool, tz *time.Location) {
+	for _, fi := range mi.fields.fieldsDB {
+		if fi.pk || !fi.inModel || isUpdated(update, fi.column) {
+			continue
+		}
+		var (
+			value interface{}
+			op    OperatorUpdate
+		)
+		switch fi.fieldType {
+		case TypeTimeField, TypeDateField, TypeDateTimeField:
+			if !fi.autoNow && !(upsert && fi.autoNowAdd) {
+				continue
+			}
+			tnow := time.Now()
+			d.ins.TimeToDB(&tnow, tz)
+			value = tnow
+			op = MgoSetOnInsert
+			if fi.autoNow {
+				op = MgoSet
+			}
+		default:
+			if fi.version {
+				// any update increases the version
+				value = 1
+				op = MgoInc
+				break
+			}
+			if !upsert || !fi.colDefault || !fi.initial.Exist() {
+				continue
+			}
+			value = getDefaultValue(fi)
+			op = MgoSetOnInsert
+		}
+		m, ok := update[string(op)].(bson.M)
+		if !ok {
+			m = bson.M{}
+			update[string(op)] = m
+		}
+		m[fi.column] = value
+	}
+}
+
+// get the version value of model.
+func getVersion(fi *fieldInfo, field reflect.Value) int64 {
+	if fi.fieldType&IsPositiveIntegerField > 0 {
+		return int64(field.Uint())
+	}
+	return field.Int()
+}
+
+// check the model has a non-zero version.
+func hasVersion(mi *modelInfo, ind reflect.Value) bool {
+	vfi := mi.fields.version
+	return vfi != nil && getVersion(vfi, ind.FieldByIndex(vfi.fieldIndex)) != 0
+}
+
+// set the version value of model.
+func setVersion(fi *fieldInfo, field reflect.Value, ver int64) {
+	if fi.fieldType&IsPositiveIntegerField > 0 {
+		field.SetUint(uint64(ver))
+	} else {
+		field.SetInt(ver)
+	}
+}
+
+// check the error is the duplicate key error of pk,
+// which is returned when the upsert filter with stale version tries to insert the existing document.
+func isDuplicatePk(err error) bool {
+	var errs []mongo.WriteError
+	switch e := err.(type) {
+	case mongo.WriteException:
+		errs = e.WriteErrors
+	case mongo.BulkWriteError:
+		errs = []mongo.WriteError{e.WriteError}
+	}
+	for _, we := range errs {
+		if (InsertFailure{Code: we.Code}).IsDuplicateKey() && strings.Contains(we.Message, " _id_ ") {
+			return true
+		}
+	}
+	return false
+}
+
+// the version is checked and increased when replacing a existing document.
+// restore is nil if model has not version field, else it must be called when the replace failed,
+// it sets the version of model back and returns ErrStaleObject if the document is stale.
+func replaceVersion(mi *modelInfo, ind reflect.Value, filter bson.M) (_ bson.M, restore func(error) error) {
+	vfi := mi.fields.version
+	if vfi == nil {
+		return filter, nil
+	}
+	vfield := ind.FieldByIndex(vfi.fieldIndex)
+	ver := getVersion(vfi, vfield)
+	setVersion(vfi, vfield, ver+1)
+	restore = func(err error) error {
+		setVersion(vfi, vfield, ver)
+		// nothing matched, or the upsert of stale version tries to insert the existing pk
+		if err == nil || err == mongo.ErrNoDocuments || isDuplicatePk(err) {
+			return ErrStaleObject
+		}
+		return err
+	}
+	return addVersionFilter(mi, filter, ver), restore
+}
+
+// add the version to filter, version 0 matches the documents without version.
+func addVersionFilter(mi *modelInfo, filter bson.M, ver int64) bson.M {
+	column := mi.fields.version.column
+	var v interface{} = ver
+	if ver == 0 {
+		v = bson.M{"$in": bson.A{0, nil}}
+	}
+	if _, ok := filter[column]; ok {
+		return bson.M{"$and": bson.A{filter, bson.M{column: v}}}
+	}
+	filter[column] = v
+	return filter
+}
+
+// check the column or its parent/child path is updated by any operator.
+func isUpdated(update bson.M, column string) bool {
+	for _, v := range update {
+		m, ok := v.(bson.M)
+		if !ok {
+			continue
+		}
+		for k := range m {
+			if k == column || strings.HasPrefix(k, column+".") || strings.HasPrefix(column, k+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// append the auto_now columns not in cols.
+func appendAutoNowCols(mi *modelInfo, cols []string) []string {
+	for _, fi := range mi.fields.fieldsDB {
+		if !fi.autoNow {
+			continue
+		}
+		found := false
+		for _, col := range cols {
+			if f, ok := mi.fields.GetByAny(col); ok && f == fi {
+				found = true
+				break
+			}
+		}
+		if !found {
+			cols = append(cols, fi.column)
+		}
+	}
+	return cols
+}
+
+// add a new pk to $setOnInsert of the upsert update when the filter has no pk,
+// so the upserted document gets a pk same as Insert instead of the server ObjectID.
+func setUpsertPk(db *mongo.Database, mi *modelInfo, filter bson.M, update bson.M) error {
+	fi := mi.fields.pk
+	if hasEqualFilter(filter, fi.column) {
+		return nil
+	}
+	if mi.idGen == nil && fi.fieldType == TypeObjectIDField {
+		return nil
+	}
+	ind := reflect.New(mi.addrField.Elem().Type()).Elem()
+	if err := setNewPk(db, mi, ind); err != nil {
+		return err
+	}
+	_, id, ok := getExistPk(mi, ind)
+	if !ok {
+		return nil
+	}
+	soi, ok := update[string(MgoSetOnInsert)].(bson.M)
+	if !ok {
+		soi = bson.M{}
+		update[string(MgoSetOnInsert)] = soi
+	}
+	if _, ok := soi[fi.column]; !ok {
+		soi[fi.column] = id
+	}
+	return nil
+}
+
+// check the filter has a equality of column, at the top level or in the $and branches.
+// the upserted document takes the value from the equality.
+func hasEqualFilter(filter bson.M, column string) bool {
+	if v, ok := filter[column]; ok {
+		m, isDoc := v.(bson.M)
+		if !isDoc || !isOperatorDoc(m) {
+			return true
+		}
+		if _, ok := m["$eq"]; ok {
+			return true
+		}
+	}
+	and, _ := filter["$and"].(bson.A)
+	for _, f := range and {
+		if m, ok := f.(bson.M); ok && hasEqualFilter(m, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// convert the filter args of ObjectID field given as hex strings to ObjectID.
+func getFilterArgs(mi *modelInfo, exprs []string, args []interface{}) []interface{} {
+	if mi == nil || len(exprs) != 1 {
+		return args
+	}
+	fi, ok := mi.fields.GetByAny(exprs[0])
+	if !ok || fi.fieldType != TypeObjectIDField {
+		return args
+	}
+	params := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		params = append(params, toObjectID(arg))
+	}
+	return params
+}
+
+// get the field of filter expression, nil if it is a nested path or unknown.
+func getFilterField(mi *modelInfo, exprs []string) *fieldInfo {
+	if mi == nil || len(exprs) != 1 {
+		return nil
+	}
+	if fi, ok := mi.fields.GetByAny(exprs[0]); ok {
+		return fi
+	}
+	return nil
+}
+
+// flatten the filter args and convert them by the field type with getFlatParams,
+// time.Time and ObjectID values are kept, the time strings of time fields are parsed to time.Time.
+func getFilterValues(fi *fieldInfo, args []interface{}, tz *time.Location) (params []interface{}) {
+	isTime := fi != nil && (fi.fieldType == TypeTimeField || fi.fieldType == TypeDateField || fi.fieldType == TypeDateTimeField)
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			params = append(params, v)
+			continue
+		case *time.Time:
+			if v != nil {
+				params = append(params, *v)
+				continue
+			}
+		case primitive.ObjectID, []byte:
+			params = append(params, v)
+			continue
+		}
+
+		val := reflect.Indirect(reflect.ValueOf(arg))
+		if val.Kind() == reflect.Slice || val.Kind() == reflect.Array {
+			elems := make([]interface{}, 0, val.Len())
+			for i := 0; i < val.Len(); i++ {
+				elems = append(elems, val.Index(i).Interface())
+			}
+			params = append(params, getFilterValues(fi, elems, tz)...)
+			continue
+		}
+
+		for _, p := range getFlatParams(fi, []interface{}{arg}, tz) {
+			if str, ok := p.(string); ok && isTime {
+				format := formatDateTime
+				if fi.fieldType == TypeDateField {
+					format = formatDate
+				} else if fi.fieldType == TypeTimeField {
+					format = formatTime
+				}
+				if t, err := time.ParseInLocation(format, str, tz); err == nil {
+					p = t
+				}
+			}
+			params = append(params, p)
+		}
+	}
+	return
+}
+
+// check the arg of isnull and exists is true, it can be bool or string like "true".
+func isTrueArg(arg interface{}) bool {
+	b, err := StrTo(ToStr(arg)).Bool()
+	return err == nil && b
+}
+
+// convert hex string or slice of hex strings to ObjectID.
+// the value is returned as it is if it is not a valid hex.
+func toObjectID(arg interface{}) interface{} {
+	switch v := arg.(type) {
+	case string:
+		if oid, err := primitive.ObjectIDFromHex(v); err == nil {
+			return oid
+		}
+	case []string:
+		a := make(bson.A, 0, len(v))
+		for _, s := range v {
+			a = append(a, toObjectID(s))
 		}
+		return a
+	case []interface{}:
+		a := make(bson.A, 0, len(v))
+		for _, s := range v {
+			a = append(a, toObjectID(s))
+		}
+		return a
 	}
+	return arg
+}
 
+func getUpdate(operator OperatorUpdate, params Params) (r bson.M) {
+	update := bson.M{}
+	for col, val := range params {
+		update[col] = val
+	}
+	r = bson.M{
+		string(operator): update,
+	}
+	setColValues(r)
 	return
 }
+
+// the pseudo operator of ColExcept, converted to aggregation pipeline by getUpdateDoc
+const mgoDivide = "$divide"
+
+// move the ColValue expressions to $inc, $mul or division.
+func setColValues(update bson.M) {
+	for op, v := range update {
+		m, ok := v.(bson.M)
+		if !ok || op == mgoDivide {
+			continue
+		}
+		for col, val := range m {
+			cv, ok := val.(colValue)
+			if !ok {
+				continue
+			}
+			delete(m, col)
+			var (
+				to    string
+				value interface{} = cv.value
+			)
+			switch cv.opt {
+			case ColAdd:
+				to = string(MgoInc)
+			case ColMinus:
+				to, value = string(MgoInc), -cv.value
+			case ColMultiply:
+				to = string(MgoMul)
+			case ColExcept:
+				to = mgoDivide
+			}
+			tm, ok := update[to].(bson.M)
+			if !ok {
+				tm = bson.M{}
+				update[to] = tm
+			}
+			tm[col] = value
+		}
+		if len(m) == 0 {
+			delete(update, op)
+		}
+	}
+}
+
+// append a $set stage of the auto_now columns and the version to pipeline,
+// the columns set by $set or $addFields stages are skipped.
+func (d *dbBaseMongo) getAutoPipeline(mi *modelInfo, stages []interface{}, tz *time.Location) bson.A {
+	updated := map[string]bool{}
+	for _, stage := range stages {
+		var doc bson.M
+		switch s := stage.(type) {
+		case bson.M:
+			doc = s
+		case map[string]interface{}:
+			doc = s
+		case bson.D:
+			doc = s.Map()
+		}
+		for _, op := range []string{"$set", "$addFields"} {
+			switch m := doc[op].(type) {
+			case bson.M:
+				for k := range m {
+					updated[k] = true
+				}
+			case map[string]interface{}:
+				for k := range m {
+					updated[k] = true
+				}
+			case bson.D:
+				for _, e := range m {
+					updated[e.Key] = true
+				}
+			}
+		}
+	}
+
+	set := bson.M{}
+	for _, fi := range mi.fields.fieldsDB {
+		if updated[fi.column] {
+			continue
+		}
+		switch {
+		case fi.autoNow:
+			tnow := time.Now()
+			d.ins.TimeToDB(&tnow, tz)
+			set[fi.column] = tnow
+		case fi.version:
+			set[fi.column] = bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + fi.column, 0}}, 1}}
+		}
+	}
+
+	pipeline := append(bson.A{}, stages...)
+	if len(set) > 0 {
+		pipeline = append(pipeline, bson.M{"$set": set})
+	}
+	return pipeline
+}
+
+// get the arrayFilters of QuerySeter.
+// the keys start with identifiers instead of model fields, so they are not mapped to columns,
+// and the values are not converted by field type, e.g. hex strings are not converted to ObjectID.
+func getArrayFilters(qs *querySet, mi *modelInfo) (af options.ArrayFilters, ok bool) {
+	if qs == nil || len(qs.arrFilters) == 0 {
+		return
+	}
+	for _, cond := range qs.arrFilters {
+		af.Filters = append(af.Filters, convertCondition(nil, cond))
+	}
+	return af, true
+}
+
+// get the document sent to server,
+// the update is converted to aggregation pipeline when it has division,
+// then the $setOnInsert fields are set only when they are missing or null.
+func getUpdateDoc(update bson.M) (interface{}, error) {
+	if _, ok := update[mgoDivide]; !ok {
+		return update, nil
+	}
+	set := bson.M{}
+	var unset bson.A
+	for op, v := range update {
+		m, _ := v.(bson.M)
+		for col, val := range m {
+			field := "$" + col
+			switch op {
+			case string(MgoSet):
+				set[col] = bson.M{"$literal": val}
+			case string(MgoInc):
+				set[col] = bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{field, 0}}, val}}
+			case string(MgoMul):
+				set[col] = bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{field, 0}}, val}}
+			case mgoDivide:
+				set[col] = bson.M{"$divide": bson.A{field, val}}
+			case string(MgoSetOnInsert):
+				// the pipeline can not tell the inserted document, the field is set only when it is missing or null
+				set[col] = bson.M{"$ifNull": bson.A{field, bson.M{"$literal": val}}}
+			case string(MgoUnSet):
+				unset = append(unset, col)
+			default:
+				return nil, ErrPipelineUpdate
+			}
+		}
+	}
+	pipeline := bson.A{}
+	if len(set) > 0 {
+		pipeline = append(pipeline, bson.M{"$set": set})
+	}
+	if len(unset) > 0 {
+		pipeline = append(pipeline, bson.M{"$unset": unset})
+	}
+	return pipeline, nil
+}
@@ -0,0 +1,198 @@
+package orm
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrNoSoftDelete = errors.New("<Ormer> model has not soft_delete field")
+)
+
+// soft delete scopes of QuerySeter
+const (
+	scopeDefault     = iota // exclude the soft deleted documents
+	scopeWithDeleted        // include the soft deleted documents
+	scopeOnlyDeleted        // only the soft deleted documents
+	scopeUnscoped           // include the soft deleted documents, and Delete removes documents
+)
+
+// get the filter of soft delete marker, nil if the model has not soft_delete field.
+func softDeleteFilter(mi *modelInfo, scope int) bson.M {
+	fi := mi.fields.softDelete
+	if fi == nil {
+		return nil
+	}
+	switch scope {
+	case scopeDefault:
+		if fi.fieldType == TypeBooleanField {
+			return bson.M{fi.column: bson.M{"$ne": true}}
+		}
+		// null, missing or zero time
+		return bson.M{fi.column: bson.M{"$not": bson.M{"$gt": time.Time{}}}}
+	case scopeOnlyDeleted:
+		if fi.fieldType == TypeBooleanField {
+			return bson.M{fi.column: true}
+		}
+		return bson.M{fi.column: bson.M{"$gt": time.Time{}}}
+	}
+	return nil
+}
+
+// add the soft delete marker filter to filter.
+func addSoftDeleteFilter(mi *modelInfo, filter bson.M, scope int) bson.M {
+	sf := softDeleteFilter(mi, scope)
+	if sf == nil {
+		return filter
+	}
+	if len(filter) == 0 {
+		return sf
+	}
+	if hasFilterColumn(filter, mi.fields.softDelete.column) {
+		// keep the marker conditions of filter and the scope both
+		if and, ok := filter["$and"].(bson.A); ok && len(filter) == 1 {
+			return bson.M{"$and": append(append(bson.A{}, and...), sf)}
+		}
+		return bson.M{"$and": bson.A{filter, sf}}
+	}
+	for k, v := range sf {
+		filter[k] = v
+	}
+	return filter
+}
+
+// check the filter has conditions of column, at the top level or in the $and, $or and $nor branches.
+func hasFilterColumn(filter bson.M, column string) bool {
+	if _, ok := filter[column]; ok {
+		return true
+	}
+	for _, op := range []string{"$and", "$or", "$nor"} {
+		branches, _ := filter[op].(bson.A)
+		for _, f := range branches {
+			if m, ok := f.(bson.M); ok && hasFilterColumn(m, column) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// get the update document which sets or clears the soft delete marker.
+func (d *dbBaseMongo) getSoftDeleteUpdate(fi *fieldInfo, tz *time.Location, restore bool) (update bson.M, now time.Time) {
+	switch {
+	case fi.fieldType == TypeBooleanField:
+		update = bson.M{"$set": bson.M{fi.column: !restore}}
+	case restore:
+		update = bson.M{"$unset": bson.M{fi.column: ""}}
+	default:
+		now = time.Now()
+		t := now
+		d.ins.TimeToDB(&t, tz)
+		update = bson.M{"$set": bson.M{fi.column: t}}
+	}
+	return
+}
+
+// set the soft delete marker of model struct.
+func setSoftDeleteField(fi *fieldInfo, ind reflect.Value, now time.Time, restore bool) {
+	field := ind.FieldByIndex(fi.fieldIndex)
+	switch {
+	case fi.fieldType == TypeBooleanField:
+		field.SetBool(!restore)
+	case restore:
+		field.Set(reflect.Zero(field.Type()))
+	case field.Kind() == reflect.Ptr:
+		field.Set(reflect.ValueOf(&now))
+	default:
+		field.Set(reflect.ValueOf(now))
+	}
+}
+
+// set or clear the soft delete marker of one record.
+func (d *dbBaseMongo) SoftDeleteOne(q dbQuerier, mi *modelInfo, ind reflect.Value, tz *time.Location, cols []string, restore bool) (cnt interface{}, err error) {
+	fi := mi.fields.softDelete
+	if fi == nil {
+		return nil, ErrNoSoftDelete
+	}
+	db := q.(*DB).MDB
+	col := db.Collection(mi.table)
+
+	var whereCols []string
+	var args []interface{}
+	if len(cols) > 0 {
+		whereCols = make([]string, 0, len(cols))
+		args, _, err = d.collectValues(mi, ind, cols, false, false, &whereCols, tz)
+		if err != nil {
+			return
+		}
+	} else {
+		// default use pk value as where condtion.
+		pkColumn, pkValue, ok := getExistPk(mi, ind)
+		if !ok {
+			return nil, ErrMissPK
+		}
+		whereCols = []string{pkColumn}
+		args = append(args, pkValue)
+	}
+
+	filter := bson.M{}
+	for i, p := range whereCols {
+		filter[p] = args[i]
+	}
+	scope := scopeDefault
+	if restore {
+		scope = scopeOnlyDeleted
+	}
+	filter = addSoftDeleteFilter(mi, filter, scope)
+	update, now := d.getSoftDeleteUpdate(fi, tz, restore)
+
+	// Do something without content
+	data, err := col.UpdateOne(todo, filter, update, options.Update())
+	if err != nil {
+		return
+	}
+	setSoftDeleteField(fi, ind, now, restore)
+	cnt = data.ModifiedCount
+	return
+}
+
+// set or clear the soft delete marker of the recodes.
+func (d *dbBaseMongo) SoftDeleteMany(qs *querySet, mi *modelInfo, cond *Condition, tz *time.Location, restore bool) (i int64, err error) {
+	fi := mi.fields.softDelete
+	if fi == nil {
+		return 0, ErrNoSoftDelete
+	}
+	db := qs.orm.db.(*DB).MDB
+	col := db.Collection(mi.table)
+
+	scope := scopeDefault
+	if restore {
+		scope = scopeOnlyDeleted
+	}
+	m := newColumnMapper(qs, mi)
+	m.scope = scope
+	filter := m.filter(cond)
+	if m.err != nil {
+		return 0, m.err
+	}
+	update, _ := d.getSoftDeleteUpdate(fi, tz, restore)
+
+	r := &mongo.UpdateResult{}
+	if qs != nil && qs.forContext {
+		r, err = col.UpdateMany(qs.ctx, filter, update, options.Update())
+	} else {
+		// Do something without content
+		r, err = col.UpdateMany(todo, filter, update, options.Update())
+	}
+	if err != nil {
+		return
+	}
+
+	i = r.ModifiedCount
+	return
+}
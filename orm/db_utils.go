@@ -18,6 +18,8 @@ import (
 	"fmt"
 	"reflect"
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // get table alias.
@@ -28,6 +30,19 @@ func getDbAlias(name string) *alias {
 	panic(fmt.Errorf("unknown DataBase alias name %s", name))
 }
 
+// get the columns of non-zero fields and the fields allow zero, except pk and version.
+func getNonZeroCols(mi *modelInfo, ind reflect.Value) (cols []string) {
+	for _, fi := range mi.fields.fieldsDB {
+		if fi.pk || fi.version || !fi.inModel {
+			continue
+		}
+		if fi.allowZero || !isZeroField(ind.FieldByIndex(fi.fieldIndex)) {
+			cols = append(cols, fi.column)
+		}
+	}
+	return
+}
+
 // get pk column info.
 func getExistPk(mi *modelInfo, ind reflect.Value) (column string, value interface{}, exist bool) {
 	fi := mi.fields.pk
@@ -44,6 +59,10 @@ func getExistPk(mi *modelInfo, ind reflect.Value) (column string, value interfac
 		value = vu
 	} else if fi.fieldType&IsRelField > 0 {
 		_, value, exist = getExistPk(fi.relModelInfo, reflect.Indirect(v))
+	} else if fi.fieldType == TypeObjectIDField {
+		vu := v.Interface().(primitive.ObjectID)
+		exist = !vu.IsZero()
+		value = vu
 	} else {
 		vu := v.String()
 		exist = vu != ""
@@ -66,6 +66,11 @@ func registerModel(PrefixOrSuffix string, model interface{}, isPrefix bool) {
 			tag := fi.sf.Tag.Get("bson")
 			// if strings.ToLower(fi.name) == "id" {
 			if strings.ToLower(tag) == "_id" {
+				if fi.fieldType == TypeObjectIDField {
+					fi.pk = true
+					mi.fields.pk = fi
+					break outFor
+				}
 				switch fi.addrValue.Elem().Kind() {
 				case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.String:
 					fi.auto = true
@@ -84,6 +89,13 @@ func registerModel(PrefixOrSuffix string, model interface{}, isPrefix bool) {
 	}
 
 	mi.table = table
+	mi.autoIncr = getAutoIncr(mi, val)
+	if gen, err := getTableIDGenerator(mi, val); err != nil {
+		fmt.Printf("<orm.RegisterModel> `%s` %s\n", name, err)
+		os.Exit(2)
+	} else {
+		mi.idGen = gen
+	}
 	mi.pkg = typ.PkgPath()
 	mi.model = model
 	mi.manual = true
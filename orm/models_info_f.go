@@ -18,6 +18,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
 	"strings"
 )
 
@@ -26,6 +27,8 @@ var errSkipField = errors.New("skip field")
 // field info collection
 type fields struct {
 	pk            *fieldInfo
+	softDelete    *fieldInfo
+	version       *fieldInfo
 	columns       map[string]*fieldInfo
 	fields        map[string]*fieldInfo
 	fieldsLow     map[string]*fieldInfo
@@ -137,6 +140,17 @@ type fieldInfo struct {
 	isFielder           bool // implement Fielder interface
 	onDelete            string
 	description         string
+	idGen               string // the id generator name of pk
+	required            bool
+	hasMin              bool
+	min                 float64 // min value of number, min length of string and slice
+	hasMax              bool
+	max                 float64 // max value of number, max length of string and slice
+	enum                []string
+	regex               *regexp.Regexp
+	softDelete          bool // the soft delete marker, bool or time
+	version             bool // the optimistic lock version, integer
+	allowZero           bool // updated by UpdateNonZero even if it is zero
 }
 
 // new field info
@@ -307,11 +321,54 @@ checkType:
 	fi.fullName = mi.fullName + mName + "." + sf.Name
 
 	fi.description = tags["description"]
+	fi.idGen = tags["idgen"]
 	fi.null = attrs["null"]
 	fi.index = attrs["index"]
 	fi.auto = attrs["auto"]
 	fi.pk = attrs["pk"]
 	fi.unique = attrs["unique"]
+	fi.required = attrs["required"]
+	fi.softDelete = attrs["soft_delete"]
+	fi.version = attrs["version"]
+	fi.allowZero = attrs["allowzero"]
+	if fi.version && fieldType&IsIntegerField == 0 {
+		err = fmt.Errorf("version field must be integer")
+		goto end
+	}
+	if fi.softDelete {
+		switch fieldType {
+		case TypeBooleanField, TypeDateField, TypeDateTimeField:
+		default:
+			err = fmt.Errorf("soft_delete field must be bool or time.Time")
+			goto end
+		}
+	}
+
+	for _, tag = range []string{"min", "max"} {
+		tagValue = tags[tag]
+		if tagValue == "" {
+			continue
+		}
+		v, e := StrTo(tagValue).Float64()
+		if e != nil {
+			err = e
+			goto wrongTag
+		}
+		if tag == "min" {
+			fi.hasMin, fi.min = true, v
+		} else {
+			fi.hasMax, fi.max = true, v
+		}
+	}
+	if tagValue = tags["enum"]; tagValue != "" {
+		fi.enum = strings.Split(tagValue, ",")
+	}
+	if tagValue = tags["regex"]; tagValue != "" {
+		if fi.regex, err = regexp.Compile(tagValue); err != nil {
+			tag = "regex"
+			goto wrongTag
+		}
+	}
 
 	// Mark object property if there is attribute "default" in the orm configuration
 	if _, ok := tags["default"]; ok {
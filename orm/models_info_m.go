@@ -32,6 +32,8 @@ type modelInfo struct {
 	addrField reflect.Value //store the original struct value
 	uniques   []string
 	isThrough bool
+	autoIncr  AutoIncr
+	idGen     IDGenerator
 }
 
 // new model info
@@ -91,6 +93,22 @@ func addModelFields(mi *modelInfo, ind reflect.Value, mName string, index []int)
 				mi.fields.pk = fi
 			}
 		}
+		if fi.version {
+			if mi.fields.version != nil {
+				err = fmt.Errorf("one model must have one version field only")
+				break
+			} else {
+				mi.fields.version = fi
+			}
+		}
+		if fi.softDelete {
+			if mi.fields.softDelete != nil {
+				err = fmt.Errorf("one model must have one soft_delete field only")
+				break
+			} else {
+				mi.fields.softDelete = fi
+			}
+		}
 	}
 
 	if err != nil {
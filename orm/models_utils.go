@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // 1 is attr
@@ -35,6 +36,10 @@ var supportTag = map[string]int{
 	"auto":         1,
 	"auto_now":     1,
 	"auto_now_add": 1,
+	"required":     1,
+	"soft_delete":  1,
+	"version":      1,
+	"allowzero":    1,
 	"size":         2,
 	"column":       2,
 	"default":      2,
@@ -47,6 +52,11 @@ var supportTag = map[string]int{
 	"on_delete":    2,
 	"type":         2,
 	"description":  2,
+	"idgen":        2,
+	"min":          2,
+	"max":          2,
+	"enum":         2,
+	"regex":        2,
 }
 
 // get reflect.Type name with package path.
@@ -167,6 +177,8 @@ func getFieldType(val reflect.Value) (ft int, err error) {
 		ft = TypeVarCharField
 	case reflect.TypeOf(new(time.Time)):
 		ft = TypeDateTimeField
+	case reflect.TypeOf(new(primitive.ObjectID)):
+		ft = TypeObjectIDField
 	default:
 		elm := reflect.Indirect(val)
 		switch elm.Kind() {
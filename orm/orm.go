@@ -9,12 +9,15 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type orm struct {
 	alias *alias
 	isTx  bool
 	db    dbQuerier
+	snaps *snapshots      // created by NewOrm, shared by the copies of WithContext
+	ctx   context.Context // nil if not set by WithContext
 }
 
 // 下划线用来判断结构体是否实现了接口，
@@ -45,6 +48,9 @@ type Params map[string]interface{}
 // ParamsList stores paramslist
 type ParamsList []interface{}
 
+// DefaultInsertChunkSize the documents sent in one InsertMany of Ormer.InsertMulti
+var DefaultInsertChunkSize = 1000
+
 const (
 	formatTime     = "15:04:05"
 	formatDate     = "2006-01-02"
@@ -71,14 +77,30 @@ func (o *orm) getMiInd(md interface{}, needPtr bool) (mi *modelInfo, ind reflect
 // read data to model
 func (o *orm) Read(md interface{}, cols ...string) (err error) {
 	mi, ind := o.getMiInd(md, true)
-	return o.alias.DbBaser.Read(o.db, mi, ind, md, o.alias.TZ, cols)
+	if err = o.alias.DbBaser.Read(o.db, mi, ind, md, o.alias.TZ, cols); err != nil {
+		return
+	}
+	o.track(mi, md)
+	return callHook(o.getContext(), o, md, hookAfterRead)
 }
 
-// Try to read a row from the database, or insert one if it doesn't exist
+// Try to read a row from the database, or insert one if it doesn't exist.
+// the soft deleted row is restored and read instead of inserting a new one.
 func (o *orm) ReadOrCreate(md interface{}, col1 string, cols ...string) (created bool, id interface{}, err error) {
 	cols = append([]string{col1}, cols...)
 	mi, ind := o.getMiInd(md, true)
 	err = o.alias.DbBaser.Read(o.db, mi, ind, md, o.alias.TZ, cols)
+	if err == mongo.ErrNoDocuments && mi.fields.softDelete != nil {
+		// restore the soft deleted document instead of inserting a duplicate
+		var cnt interface{}
+		if cnt, err = o.alias.DbBaser.SoftDeleteOne(o.db, mi, ind, o.alias.TZ, cols, true); err != nil {
+			return
+		}
+		err = mongo.ErrNoDocuments
+		if n, _ := cnt.(int64); n > 0 {
+			err = o.alias.DbBaser.Read(o.db, mi, ind, md, o.alias.TZ, cols)
+		}
+	}
 	if err == mongo.ErrNoDocuments {
 		// Create
 		id, err = o.Insert(md)
@@ -90,6 +112,12 @@ func (o *orm) ReadOrCreate(md interface{}, col1 string, cols ...string) (created
 		id = int64(vid.Uint())
 	} else if mi.fields.pk.rel {
 		return o.ReadOrCreate(vid.Interface(), mi.fields.pk.relModelInfo.fields.pk.name)
+	} else {
+		_, id, _ = getExistPk(mi, ind)
+	}
+	if err == nil {
+		o.track(mi, md)
+		err = callHook(o.getContext(), o, md, hookAfterRead)
 	}
 
 	return false, id, err
@@ -98,12 +126,20 @@ func (o *orm) ReadOrCreate(md interface{}, col1 string, cols ...string) (created
 // insert model data to database
 func (o *orm) Insert(md interface{}) (id interface{}, err error) {
 	mi, ind := o.getMiInd(md, true)
-	id, err = o.alias.DbBaser.InsertOne(o.db, mi, ind, md, o.alias.TZ)
+	if err = callHook(o.getContext(), o, md, hookBeforeInsert); err != nil {
+		return
+	}
+	if id, err = o.alias.DbBaser.InsertOne(o.db, mi, ind, md, o.alias.TZ); err != nil {
+		return
+	}
+	err = callHook(o.getContext(), o, md, hookAfterInsert)
 	return
 }
 
-// insert models data to database
-func (o *orm) InsertMulti(mds interface{}) (ids interface{}, err error) {
+// insert models data to database.
+// mds can be []T, []*T or pointer of them, the empty pks are generated and set back to mds.
+// the documents are inserted chunk by chunk, failed documents are listed in InsertMultiResult.Failed.
+func (o *orm) InsertMulti(mds interface{}, opts ...InsertMultiOptions) (res *InsertMultiResult, err error) {
 	sind := reflect.Indirect(reflect.ValueOf(mds))
 	switch sind.Kind() {
 	case reflect.Array, reflect.Slice:
@@ -113,23 +149,182 @@ func (o *orm) InsertMulti(mds interface{}) (ids interface{}, err error) {
 	default:
 		return nil, ErrArgs
 	}
-	ind := reflect.Indirect(sind.Index(0))
-	mi, _ := o.getMiInd(ind.Interface(), false)
-	ids, err = o.alias.DbBaser.InsertMany(o.db, mi, ind, mds, o.alias.TZ)
+
+	var io InsertMultiOptions
+	if len(opts) > 0 {
+		io = opts[0]
+	}
+
+	var mi *modelInfo
+	inds := make([]reflect.Value, 0, sind.Len())
+	for i := 0; i < sind.Len(); i++ {
+		v := sind.Index(i)
+		if v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, ErrArgs
+		}
+		ind := reflect.Indirect(v)
+		if !ind.CanAddr() {
+			return nil, ErrArgs
+		}
+		m, _ := o.getMiInd(ind.Interface(), false)
+		if mi == nil {
+			mi = m
+		} else if mi != m {
+			panic(fmt.Errorf("<Ormer.InsertMulti> all models must be `%s`, but found `%s`", mi.fullName, m.fullName))
+		}
+		inds = append(inds, ind)
+	}
+
+	for _, ind := range inds {
+		if err = callHook(o.getContext(), o, ind.Addr().Interface(), hookBeforeInsert); err != nil {
+			return
+		}
+	}
+	res, err = o.alias.DbBaser.InsertMany(o.db, mi, inds, io, o.alias.TZ)
+	if res == nil {
+		return
+	}
+	// only the inserted documents have id
+	for i, ind := range inds {
+		if i >= len(res.Ids) || res.Ids[i] == nil {
+			continue
+		}
+		if e := callHook(o.getContext(), o, ind.Addr().Interface(), hookAfterInsert); e != nil {
+			return res, e
+		}
+	}
 	return
 }
 
 // cols set the columns those want to update.
-func (o *orm) Update(md interface{}, cols ...string) (interface{}, error) {
+// in tracking mode, only the fields changed since read are updated when cols is empty.
+func (o *orm) Update(md interface{}, cols ...string) (res interface{}, err error) {
+	mi, ind := o.getMiInd(md, true)
+	if err = callHook(o.getContext(), o, md, hookBeforeUpdate); err != nil {
+		return
+	}
+
+	var (
+		unsets  []string
+		changed bool
+	)
+	if len(cols) == 0 {
+		if old, ok := o.snapshot(md); ok {
+			cols, unsets, changed = diffSnapshot(mi, old, md)
+		}
+	}
+	if changed {
+		if len(cols) == 0 && len(unsets) == 0 {
+			// nothing changed
+			return
+		}
+		res, err = o.alias.DbBaser.UpdateChanged(o.db, mi, ind, md, o.alias.TZ, cols, unsets, true)
+	} else {
+		res, err = o.alias.DbBaser.UpdateOne(o.db, mi, ind, md, o.alias.TZ, cols)
+	}
+	if err != nil {
+		return
+	}
+	if _, ok := o.snapshot(md); ok {
+		o.track(mi, md)
+	}
+	err = callHook(o.getContext(), o, md, hookAfterUpdate)
+	return
+}
+
+// update the non-zero fields of model, the fields with tag `orm:"allowzero"` are always updated.
+// it is used to save the partial model, e.g. bind from PATCH request.
+// the version is checked only when it is not zero, so the client sends the version to detect stale updates,
+// the partial model without version updates the document unconditionally and its version is not changed.
+func (o *orm) UpdateNonZero(md interface{}) (res interface{}, err error) {
+	mi, ind := o.getMiInd(md, true)
+	if err = callHook(o.getContext(), o, md, hookBeforeUpdate); err != nil {
+		return
+	}
+	cols := getNonZeroCols(mi, ind)
+	if len(cols) == 0 {
+		// nothing to update
+		return
+	}
+	if res, err = o.alias.DbBaser.UpdateChanged(o.db, mi, ind, md, o.alias.TZ, cols, nil, hasVersion(mi, ind)); err != nil {
+		return
+	}
+	o.untrack(md)
+	err = callHook(o.getContext(), o, md, hookAfterUpdate)
+	return
+}
+
+// update the document matched by pk with aggregation pipeline stages, it needs MongoDB 4.2+.
+// the fields computed by server are not read back to model.
+func (o *orm) UpdatePipeline(md interface{}, stages ...interface{}) (cnt int64, err error) {
 	mi, ind := o.getMiInd(md, true)
-	return o.alias.DbBaser.UpdateOne(o.db, mi, ind, md, o.alias.TZ, cols)
+	if err = callHook(o.getContext(), o, md, hookBeforeUpdate); err != nil {
+		return
+	}
+	if cnt, err = o.alias.DbBaser.UpdatePipelineOne(o.db, mi, ind, o.alias.TZ, stages); err != nil {
+		return
+	}
+	o.untrack(md)
+	err = callHook(o.getContext(), o, md, hookAfterUpdate)
+	return
+}
+
+// replace the document matched by pk with the whole model struct,
+// fields absent from the struct are removed from the document.
+// use options.Replace().SetUpsert(true) to insert it when not exists.
+func (o *orm) Replace(md interface{}, opts ...*options.ReplaceOptions) (id interface{}, err error) {
+	mi, ind := o.getMiInd(md, true)
+	if err = callHook(o.getContext(), o, md, hookBeforeUpdate); err != nil {
+		return
+	}
+	if id, err = o.alias.DbBaser.ReplaceOne(o.db, mi, ind, md, o.alias.TZ, opts); err != nil {
+		return
+	}
+	if _, ok := o.snapshot(md); ok {
+		o.track(mi, md)
+	}
+	err = callHook(o.getContext(), o, md, hookAfterUpdate)
+	return
 }
 
 // delete model in database
 // cols shows the delete conditions values read from. default is pk
+// the model with soft_delete field is marked as deleted instead.
 func (o *orm) Delete(md interface{}, cols ...string) (interface{}, error) {
+	return o.delete(md, false, cols)
+}
+
+// delete model in database even if it has soft_delete field.
+func (o *orm) ForceDelete(md interface{}, cols ...string) (interface{}, error) {
+	return o.delete(md, true, cols)
+}
+
+func (o *orm) delete(md interface{}, force bool, cols []string) (res interface{}, err error) {
+	mi, ind := o.getMiInd(md, true)
+	if err = callHook(o.getContext(), o, md, hookBeforeDelete); err != nil {
+		return
+	}
+	if mi.fields.softDelete != nil && !force {
+		res, err = o.alias.DbBaser.SoftDeleteOne(o.db, mi, ind, o.alias.TZ, cols, false)
+	} else {
+		res, err = o.alias.DbBaser.DeleteOne(o.db, mi, ind, md, o.alias.TZ, cols)
+	}
+	if err != nil {
+		return
+	}
+	o.untrack(md)
+	err = callHook(o.getContext(), o, md, hookAfterDelete)
+	return
+}
+
+// restore the soft deleted model, clear the soft_delete field.
+// cols shows the conditions values read from. default is pk
+func (o *orm) Restore(md interface{}, cols ...string) (interface{}, error) {
 	mi, ind := o.getMiInd(md, true)
-	return o.alias.DbBaser.DeleteOne(o.db, mi, ind, md, o.alias.TZ, cols)
+	return o.alias.DbBaser.SoftDeleteOne(o.db, mi, ind, o.alias.TZ, cols, true)
 }
 
 // set auto pk field
@@ -143,32 +338,60 @@ func (o *orm) setPk(mi *modelInfo, ind reflect.Value, id int64) {
 	}
 }
 
-// return a QuerySeter for table operations.
-// table name can be string or struct.
-// e.g. QueryTable("user"), QueryTable(&user{}) or QueryTable((*User)(nil)),
-func (o *orm) QueryTable(ptrStructOrTableName interface{}) (qs QuerySeter) {
-	var name string
-	if table, ok := ptrStructOrTableName.(string); ok {
+// get model info by table name or struct.
+func (o *orm) getMiByTable(ptrStructOrTableName interface{}) (mi *modelInfo, name string, ok bool) {
+	if table, isStr := ptrStructOrTableName.(string); isStr {
 		name = nameStrategyMap[MongoNameStrategy](table)
-		if mi, ok := modelCache.get(name); ok {
-			qs = newQuerySet(o, mi)
-		}
+		mi, ok = modelCache.get(name)
 	} else {
 		name = getFullName(indirectType(reflect.TypeOf(ptrStructOrTableName)))
-		if mi, ok := modelCache.getByFullName(name); ok {
-			qs = newQuerySet(o, mi)
-		}
+		mi, ok = modelCache.getByFullName(name)
 	}
-	if qs == nil {
+	return
+}
+
+// return a QuerySeter for table operations.
+// table name can be string or struct.
+// e.g. QueryTable("user"), QueryTable(&user{}) or QueryTable((*User)(nil)),
+func (o *orm) QueryTable(ptrStructOrTableName interface{}) (qs QuerySeter) {
+	mi, name, ok := o.getMiByTable(ptrStructOrTableName)
+	if !ok {
 		panic(fmt.Errorf("<Ormer.QueryTable> table name: `%s` not exists", name))
 	}
-	return
+	return newQuerySet(o, mi)
+}
+
+// return a BulkWriter to queue mixed write operations for one table.
+// table name can be string or struct, same as QueryTable.
+func (o *orm) Bulk(ptrStructOrTableName interface{}) BulkWriter {
+	mi, name, ok := o.getMiByTable(ptrStructOrTableName)
+	if !ok {
+		panic(fmt.Errorf("<Ormer.Bulk> table name: `%s` not exists", name))
+	}
+	return newBulkWriter(o, mi)
+}
+
+// return a copy of Ormer passing ctx to the hooks of models,
+// the copy shares the db connection and tracking snapshots of o.
+func (o *orm) WithContext(ctx context.Context) Ormer {
+	c := *o
+	c.ctx = ctx
+	return &c
+}
+
+// get the context set by WithContext, default is todo.
+func (o *orm) getContext() context.Context {
+	if o.ctx != nil {
+		return o.ctx
+	}
+	return todo
 }
 
 func NewOrm() Ormer {
 	BootStrap() // execute only once
 
 	o := new(orm)
+	o.snaps = newSnapshots()
 	err := o.Using("default")
 	if err != nil {
 		panic(err)
@@ -0,0 +1,261 @@
+package orm
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrBulkEmpty   = errors.New("<BulkWriter> have not any operation")
+	ErrBulkSkipped = errors.New("<BulkWriter> operation not executed because of a previous error in ordered mode")
+)
+
+// bulk operation names
+const (
+	BulkInsertOne  = "insertOne"
+	BulkUpdateOne  = "updateOne"
+	BulkUpdateMany = "updateMany"
+	BulkReplaceOne = "replaceOne"
+	BulkDeleteOne  = "deleteOne"
+	BulkDeleteMany = "deleteMany"
+)
+
+// BulkOpResult the result of one queued operation
+type BulkOpResult struct {
+	Index int         // index of the operation in the bulk
+	Op    string      // the operation name, e.g. BulkInsertOne
+	Id    interface{} // inserted or upserted id, nil if none
+	Err   error       // write error of this operation, nil if succeed
+}
+
+// BulkResult the result of BulkWriter.Exec
+type BulkResult struct {
+	InsertedCount int64
+	MatchedCount  int64
+	ModifiedCount int64
+	DeletedCount  int64
+	UpsertedCount int64
+	Ops           []BulkOpResult
+}
+
+// real bulk struct
+type bulkWriter struct {
+	mi      *modelInfo
+	orm     *orm
+	ordered bool
+	models  []mongo.WriteModel
+	ops     []string
+	ids     []interface{}
+	err     error // the first error when queue operations
+}
+
+var _ BulkWriter = new(bulkWriter)
+
+// check the model struct belongs to the bulk table
+func (b *bulkWriter) getInd(md interface{}) reflect.Value {
+	mi, ind := b.orm.getMiInd(md, true)
+	if mi != b.mi {
+		panic(fmt.Errorf("<BulkWriter> model `%s` is not belongs to table `%s`", mi.fullName, b.mi.table))
+	}
+	return ind
+}
+
+// the dbBaser sets the auto values of models.
+func (b *bulkWriter) base() *dbBaseMongo {
+	return b.orm.alias.DbBaser.(*dbBaseMongo)
+}
+
+func (b *bulkWriter) add(op string, model mongo.WriteModel, id interface{}) BulkWriter {
+	b.models = append(b.models, model)
+	b.ops = append(b.ops, op)
+	b.ids = append(b.ids, id)
+	return b
+}
+
+// queue a insert operation, the pk is generated when empty.
+func (b *bulkWriter) InsertOne(md interface{}) BulkWriter {
+	ind := b.getInd(md)
+	b.base().setAutoValues(b.mi, ind, true, b.orm.alias.TZ)
+	b.setErr(validateModel(b.mi, ind, nil))
+	b.setErr(setNewPk(b.orm.db.(*DB).MDB, b.mi, ind))
+	_, id, _ := getExistPk(b.mi, ind)
+	return b.add(BulkInsertOne, mongo.NewInsertOneModel().SetDocument(md), id)
+}
+
+// convert the condition to filter, the soft deleted documents are excluded like QuerySeter.
+func (b *bulkWriter) filter(cond *Condition) bson.M {
+	return addSoftDeleteFilter(b.mi, convertCondition(b.mi, cond), scopeDefault)
+}
+
+// keep the first error when queue operations.
+func (b *bulkWriter) setErr(err error) {
+	if err != nil && b.err == nil {
+		b.err = err
+	}
+}
+
+// get the update document, a new pk is set on insert for upsert.
+func (b *bulkWriter) getUpdate(filter bson.M, operator OperatorUpdate, values Params, upsert []bool) interface{} {
+	b.setErr(validateParams(b.mi, operator, values))
+	update := getUpdate(operator, values)
+	cm := newColumnMapper(nil, b.mi)
+	cm.updateKeys(update)
+	b.setErr(cm.err)
+	up := len(upsert) > 0 && upsert[0]
+	b.base().setAutoUpdate(b.mi, update, up, b.orm.alias.TZ)
+	if up {
+		b.setErr(setUpsertPk(b.orm.db.(*DB).MDB, b.mi, filter, update))
+	}
+	doc, err := getUpdateDoc(update)
+	b.setErr(err)
+	return doc
+}
+
+// queue a update operation for the first document matches cond.
+func (b *bulkWriter) UpdateOne(cond *Condition, operator OperatorUpdate, values Params, upsert ...bool) BulkWriter {
+	filter := b.filter(cond)
+	model := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(b.getUpdate(filter, operator, values, upsert))
+	if len(upsert) > 0 {
+		model.SetUpsert(upsert[0])
+	}
+	return b.add(BulkUpdateOne, model, nil)
+}
+
+// queue a update operation for all documents match cond.
+func (b *bulkWriter) UpdateMany(cond *Condition, operator OperatorUpdate, values Params, upsert ...bool) BulkWriter {
+	filter := b.filter(cond)
+	model := mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(b.getUpdate(filter, operator, values, upsert))
+	if len(upsert) > 0 {
+		model.SetUpsert(upsert[0])
+	}
+	return b.add(BulkUpdateMany, model, nil)
+}
+
+// queue a replace operation, the document is matched by the pk of model struct.
+// the pk is generated for upsert when it is empty.
+func (b *bulkWriter) ReplaceOne(md interface{}, upsert ...bool) BulkWriter {
+	ind := b.getInd(md)
+	// the bulk result has not the matched count of each operation, so the version can't be checked
+	if b.mi.fields.version != nil {
+		b.setErr(fmt.Errorf("<BulkWriter.ReplaceOne> model `%s` has version field, use Ormer.Replace instead", b.mi.fullName))
+		return b
+	}
+	_, _, ok := getExistPk(b.mi, ind)
+	if !ok {
+		if len(upsert) == 0 || !upsert[0] {
+			b.setErr(ErrHaveNoPK)
+			return b
+		}
+		b.setErr(setNewPk(b.orm.db.(*DB).MDB, b.mi, ind))
+	}
+	b.base().setAutoValues(b.mi, ind, !ok, b.orm.alias.TZ)
+	b.setErr(validateModel(b.mi, ind, nil))
+	c, val, _ := getExistPk(b.mi, ind)
+	filter := addSoftDeleteFilter(b.mi, bson.M{c: val}, scopeDefault)
+	model := mongo.NewReplaceOneModel().SetFilter(filter).SetReplacement(md)
+	if len(upsert) > 0 {
+		model.SetUpsert(upsert[0])
+	}
+	return b.add(BulkReplaceOne, model, nil)
+}
+
+// queue a delete operation for the first document matches cond.
+// the document of model with soft_delete field is marked as deleted.
+func (b *bulkWriter) DeleteOne(cond *Condition) BulkWriter {
+	filter := b.filter(cond)
+	if fi := b.mi.fields.softDelete; fi != nil {
+		update, _ := b.base().getSoftDeleteUpdate(fi, b.orm.alias.TZ, false)
+		return b.add(BulkDeleteOne, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update), nil)
+	}
+	return b.add(BulkDeleteOne, mongo.NewDeleteOneModel().SetFilter(filter), nil)
+}
+
+// queue a delete operation for all documents match cond.
+// the documents of model with soft_delete field are marked as deleted.
+func (b *bulkWriter) DeleteMany(cond *Condition) BulkWriter {
+	filter := b.filter(cond)
+	if fi := b.mi.fields.softDelete; fi != nil {
+		update, _ := b.base().getSoftDeleteUpdate(fi, b.orm.alias.TZ, false)
+		return b.add(BulkDeleteMany, mongo.NewUpdateManyModel().SetFilter(filter).SetUpdate(update), nil)
+	}
+	return b.add(BulkDeleteMany, mongo.NewDeleteManyModel().SetFilter(filter), nil)
+}
+
+// set ordered mode, default is true.
+// in ordered mode the bulk stops at the first error.
+func (b *bulkWriter) Ordered(ordered bool) BulkWriter {
+	b.ordered = ordered
+	return b
+}
+
+// count of queued operations
+func (b *bulkWriter) Len() int {
+	return len(b.models)
+}
+
+// execute all queued operations.
+// the result is returned even when some operations failed.
+func (b *bulkWriter) Exec() (*BulkResult, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if len(b.models) == 0 {
+		return nil, ErrBulkEmpty
+	}
+	return b.orm.alias.DbBaser.BulkWrite(b.orm.db, b.mi, b.models, b.ordered, b.orm.alias.TZ, b.ops, b.ids)
+}
+
+// create new BulkWriter.
+func newBulkWriter(orm *orm, mi *modelInfo) BulkWriter {
+	b := new(bulkWriter)
+	b.mi = mi
+	b.orm = orm
+	b.ordered = true
+	return b
+}
+
+// convert mongo bulk result and error to BulkResult
+func newBulkResult(r *mongo.BulkWriteResult, err error, ordered bool, ops []string, ids []interface{}) *BulkResult {
+	res := new(BulkResult)
+	if r != nil {
+		res.InsertedCount = r.InsertedCount
+		res.MatchedCount = r.MatchedCount
+		res.ModifiedCount = r.ModifiedCount
+		res.DeletedCount = r.DeletedCount
+		res.UpsertedCount = r.UpsertedCount
+	}
+
+	errs := map[int]error{}
+	failed := len(ops)
+	if e, ok := err.(mongo.BulkWriteException); ok {
+		for _, we := range e.WriteErrors {
+			errs[we.Index] = we
+			if we.Index < failed {
+				failed = we.Index
+			}
+		}
+	}
+
+	res.Ops = make([]BulkOpResult, 0, len(ops))
+	for i, op := range ops {
+		or := BulkOpResult{Index: i, Op: op}
+		if e, ok := errs[i]; ok {
+			or.Err = e
+		} else if err != nil && len(errs) == 0 {
+			// the whole bulk failed
+			or.Err = err
+		} else if ordered && i > failed {
+			or.Err = ErrBulkSkipped
+		} else if op == BulkInsertOne {
+			or.Id = ids[i]
+		} else if r != nil {
+			or.Id = r.UpsertedIDs[int64(i)]
+		}
+		res.Ops = append(res.Ops, or)
+	}
+	return res
+}
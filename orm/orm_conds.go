@@ -17,6 +17,8 @@ package orm
 import (
 	"fmt"
 	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
 )
 
 // ExprSep define the expression separation
@@ -74,6 +76,12 @@ func (c Condition) AndNot(expr string, args ...interface{}) *Condition {
 	return &c
 }
 
+// ToBSON return the filter document of condition, the field names are not mapped to columns.
+// it is useful for debugging.
+func (c *Condition) ToBSON() bson.M {
+	return convertCondition(nil, c)
+}
+
 // AndCond combine a condition to current condition
 func (c *Condition) AndCond(cond *Condition) *Condition {
 	c = c.clone()
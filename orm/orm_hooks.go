@@ -0,0 +1,115 @@
+package orm
+
+import (
+	"context"
+	"reflect"
+)
+
+// BeforeInserter is called by Ormer.Insert, InsertMulti and ReadOrCreate before the model is inserted,
+// returning an error aborts the insert.
+type BeforeInserter interface {
+	BeforeInsert(ctx context.Context, o Ormer) error
+}
+
+// AfterInserter is called after the model is inserted.
+type AfterInserter interface {
+	AfterInsert(ctx context.Context, o Ormer) error
+}
+
+// BeforeUpdater is called by Ormer.Update and Replace before the model is updated,
+// returning an error aborts the update.
+type BeforeUpdater interface {
+	BeforeUpdate(ctx context.Context, o Ormer) error
+}
+
+// AfterUpdater is called after the model is updated.
+type AfterUpdater interface {
+	AfterUpdate(ctx context.Context, o Ormer) error
+}
+
+// BeforeDeleter is called by Ormer.Delete before the model is deleted,
+// returning an error aborts the delete.
+type BeforeDeleter interface {
+	BeforeDelete(ctx context.Context, o Ormer) error
+}
+
+// AfterDeleter is called after the model is deleted.
+type AfterDeleter interface {
+	AfterDelete(ctx context.Context, o Ormer) error
+}
+
+// AfterReader is called by Ormer.Read, ReadOrCreate, QuerySeter.One and All after the model is read.
+type AfterReader interface {
+	AfterRead(ctx context.Context, o Ormer) error
+}
+
+// hook kinds
+const (
+	hookBeforeInsert = iota
+	hookAfterInsert
+	hookBeforeUpdate
+	hookAfterUpdate
+	hookBeforeDelete
+	hookAfterDelete
+	hookAfterRead
+)
+
+// call the hook of model if it implements.
+func callHook(ctx context.Context, o Ormer, md interface{}, hook int) error {
+	switch hook {
+	case hookBeforeInsert:
+		if h, ok := md.(BeforeInserter); ok {
+			return h.BeforeInsert(ctx, o)
+		}
+	case hookAfterInsert:
+		if h, ok := md.(AfterInserter); ok {
+			return h.AfterInsert(ctx, o)
+		}
+	case hookBeforeUpdate:
+		if h, ok := md.(BeforeUpdater); ok {
+			return h.BeforeUpdate(ctx, o)
+		}
+	case hookAfterUpdate:
+		if h, ok := md.(AfterUpdater); ok {
+			return h.AfterUpdate(ctx, o)
+		}
+	case hookBeforeDelete:
+		if h, ok := md.(BeforeDeleter); ok {
+			return h.BeforeDelete(ctx, o)
+		}
+	case hookAfterDelete:
+		if h, ok := md.(AfterDeleter); ok {
+			return h.AfterDelete(ctx, o)
+		}
+	case hookAfterRead:
+		if h, ok := md.(AfterReader); ok {
+			return h.AfterRead(ctx, o)
+		}
+	}
+	return nil
+}
+
+// call AfterRead of the models read into container,
+// container can be pointer of struct, []T or []*T.
+func callAfterRead(ctx context.Context, o Ormer, container interface{}) error {
+	val := reflect.ValueOf(container)
+	ind := reflect.Indirect(val)
+	if ind.Kind() != reflect.Slice && ind.Kind() != reflect.Array {
+		return callHook(ctx, o, container, hookAfterRead)
+	}
+	for i := 0; i < ind.Len(); i++ {
+		v := ind.Index(i)
+		if v.Kind() != reflect.Ptr {
+			if !v.CanAddr() {
+				continue
+			}
+			v = v.Addr()
+		} else if v.IsNil() {
+			continue
+		}
+		if err := callHook(ctx, o, v.Interface(), hookAfterRead); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package orm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// built-in id generator names
+const (
+	IDGenObjectID  = "objectid"
+	IDGenUUID      = "uuid"
+	IDGenUUIDv4    = "uuidv4"
+	IDGenUUIDv7    = "uuidv7"
+	IDGenULID      = "ulid"
+	IDGenSnowflake = "snowflake"
+)
+
+// IDGenerator generate the pk value of new documents.
+// the value can be string, integer or primitive.ObjectID and is converted to the pk type.
+// a model selects its generator by the method TableIDGenerator or the pk tag `orm:"idgen(name)"`.
+type IDGenerator interface {
+	NewID() (interface{}, error)
+}
+
+// IDGeneratorFunc the function adapter of IDGenerator
+type IDGeneratorFunc func() (interface{}, error)
+
+// NewID call the function
+func (f IDGeneratorFunc) NewID() (interface{}, error) {
+	return f()
+}
+
+var (
+	idGenerators = &_idGenCache{cache: map[string]IDGenerator{
+		IDGenObjectID:  IDGeneratorFunc(newObjectID),
+		IDGenUUID:      IDGeneratorFunc(newUUIDv4),
+		IDGenUUIDv4:    IDGeneratorFunc(newUUIDv4),
+		IDGenUUIDv7:    IDGeneratorFunc(newUUIDv7),
+		IDGenULID:      IDGeneratorFunc(newULID),
+		IDGenSnowflake: NewSnowflakeGenerator(-1),
+	}}
+)
+
+// id generator cacher.
+type _idGenCache struct {
+	mux   sync.RWMutex
+	cache map[string]IDGenerator
+}
+
+// RegisterIDGenerator register a named id generator, the built-in ones can be replaced.
+// it must be run before RegisterModel of the models use it.
+func RegisterIDGenerator(name string, gen IDGenerator) {
+	if gen == nil {
+		panic(fmt.Errorf("<orm.RegisterIDGenerator> generator `%s` cannot be nil", name))
+	}
+	idGenerators.mux.Lock()
+	defer idGenerators.mux.Unlock()
+	idGenerators.cache[name] = gen
+}
+
+// GetIDGenerator get a registered id generator by name
+func GetIDGenerator(name string) (gen IDGenerator, ok bool) {
+	idGenerators.mux.RLock()
+	defer idGenerators.mux.RUnlock()
+	gen, ok = idGenerators.cache[name]
+	return
+}
+
+// get the id generator of model from method TableIDGenerator or pk tag idgen.
+func getTableIDGenerator(mi *modelInfo, val reflect.Value) (gen IDGenerator, err error) {
+	if fun := val.MethodByName("TableIDGenerator"); fun.IsValid() {
+		vals := fun.Call([]reflect.Value{})
+		if len(vals) > 0 && vals[0].CanInterface() {
+			if d, ok := vals[0].Interface().(IDGenerator); ok && d != nil {
+				return d, nil
+			}
+		}
+	}
+	if name := mi.fields.pk.idGen; name != "" {
+		var ok bool
+		if gen, ok = GetIDGenerator(name); !ok {
+			err = fmt.Errorf("unknown id generator `%s`, make sure it was registered with `RegisterIDGenerator()`", name)
+		}
+	}
+	return
+}
+
+// set the generated id to pk field, converting to the pk type.
+// the integer ids of any kind are accepted if they fit in the pk field.
+func setPkValue(fi *fieldInfo, field reflect.Value, id interface{}) error {
+	switch v := id.(type) {
+	case primitive.ObjectID:
+		switch {
+		case fi.fieldType == TypeObjectIDField:
+			field.Set(reflect.ValueOf(v))
+			return nil
+		case field.Kind() == reflect.String:
+			field.SetString(v.Hex())
+			return nil
+		}
+	case string:
+		switch {
+		case fi.fieldType == TypeObjectIDField:
+			oid, err := primitive.ObjectIDFromHex(v)
+			if err != nil {
+				return err
+			}
+			field.Set(reflect.ValueOf(oid))
+			return nil
+		case field.Kind() == reflect.String:
+			field.SetString(v)
+			return nil
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		val := reflect.ValueOf(v)
+		var str string
+		var n int64
+		var u uint64
+		var neg, big bool
+		if val.Kind() >= reflect.Int && val.Kind() <= reflect.Int64 {
+			n = val.Int()
+			u, neg = uint64(n), n < 0
+			str = strconv.FormatInt(n, 10)
+		} else {
+			u = val.Uint()
+			n, big = int64(u), u > math.MaxInt64
+			str = strconv.FormatUint(u, 10)
+		}
+		switch {
+		case fi.fieldType&IsPositiveIntegerField > 0:
+			if !neg && !field.OverflowUint(u) {
+				field.SetUint(u)
+				return nil
+			}
+		case fi.fieldType&IsIntegerField > 0:
+			if !big && !field.OverflowInt(n) {
+				field.SetInt(n)
+				return nil
+			}
+		case field.Kind() == reflect.String:
+			field.SetString(str)
+			return nil
+		}
+	}
+	return fmt.Errorf("<IDGenerator> cannot set id `%v` (%T) to pk `%s`", id, id, fi.fullName)
+}
+
+func newObjectID() (interface{}, error) {
+	return primitive.NewObjectID(), nil
+}
+
+// random UUID version 4.
+func newUUIDv4() (interface{}, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+// time ordered UUID version 7.
+func newUUIDv7() (interface{}, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return nil, err
+	}
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	b[0] = byte(ms >> 40)
+	b[1] = byte(ms >> 32)
+	b[2] = byte(ms >> 24)
+	b[3] = byte(ms >> 16)
+	b[4] = byte(ms >> 8)
+	b[5] = byte(ms)
+	b[6] = b[6]&0x0f | 0x70
+	b[8] = b[8]&0x3f | 0x80
+	return formatUUID(b), nil
+}
+
+func formatUUID(b [16]byte) string {
+	var s [36]byte
+	hex.Encode(s[0:8], b[0:4])
+	s[8] = '-'
+	hex.Encode(s[9:13], b[4:6])
+	s[13] = '-'
+	hex.Encode(s[14:18], b[6:8])
+	s[18] = '-'
+	hex.Encode(s[19:23], b[8:10])
+	s[23] = '-'
+	hex.Encode(s[24:], b[10:])
+	return string(s[:])
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ULID, 48 bits milliseconds and 80 bits randomness in Crockford's base32.
+func newULID() (interface{}, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[6:]); err != nil {
+		return nil, err
+	}
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	binary.BigEndian.PutUint16(b[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(b[2:6], uint32(ms))
+
+	hi := binary.BigEndian.Uint64(b[:8])
+	lo := binary.BigEndian.Uint64(b[8:])
+	var s [26]byte
+	for i := 25; i >= 0; i-- {
+		s[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(s[:]), nil
+}
+
+// snowflake epoch, 2020-01-01 00:00:00 UTC in milliseconds
+const snowflakeEpoch = 1577836800000
+
+// snowflake id generator.
+// 41 bits milliseconds since 2020-01-01, 10 bits node and 12 bits sequence.
+type snowflake struct {
+	sync.Mutex
+	node int64
+	ms   int64
+	seq  int64
+	err  error // the error of random node, returned by NewID
+}
+
+// NewSnowflakeGenerator create a snowflake id generator with node id in [0, 1023].
+// a random node is used if node is negative.
+func NewSnowflakeGenerator(node int64) IDGenerator {
+	if node < 0 {
+		var b [2]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return &snowflake{err: err}
+		}
+		node = int64(binary.BigEndian.Uint16(b[:]))
+	}
+	return &snowflake{node: node & 0x3ff}
+}
+
+// NewID return a int64 id
+func (s *snowflake) NewID() (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	s.Lock()
+	defer s.Unlock()
+	ms := time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+	if ms < s.ms {
+		// clock moved backwards, keep the last time
+		ms = s.ms
+	}
+	if ms == s.ms {
+		s.seq = (s.seq + 1) & 0xfff
+		if s.seq == 0 {
+			// sequence exhausted, wait for the next millisecond
+			for ms <= s.ms {
+				time.Sleep(100 * time.Microsecond)
+				ms = time.Now().UnixNano()/int64(time.Millisecond) - snowflakeEpoch
+			}
+		}
+	} else {
+		s.seq = 0
+	}
+	s.ms = ms
+	return ms<<22 | s.node<<12 | s.seq, nil
+}
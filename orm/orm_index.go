@@ -2,6 +2,8 @@ package orm
 
 import (
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
@@ -13,8 +15,19 @@ var (
 	ErrNoIndexKey = errors.New("have not a index key")
 )
 
+var (
+	ErrIndexKeyType = errors.New("unknown index type")
+
+	indexTypes = map[string]bool{
+		"text":     true,
+		"2dsphere": true,
+		"2d":       true,
+		"hashed":   true,
+	}
+)
+
 type Index struct {
-	Keys []string
+	Keys []string // ordered key specs, e.g. "-created", "title:text", "$**"
 	options.IndexOptions
 }
 type indexView struct {
@@ -109,23 +122,45 @@ func newIndexView(iv mongo.IndexView) IndexViewer {
 	return v
 }
 
-func convertIndex(index Index) (keys bson.M, iopts *options.IndexOptions, err error) {
+func convertIndex(index Index) (keys bson.D, iopts *options.IndexOptions, err error) {
 	if len(index.Keys) < 1 {
 		err = ErrNoIndexKey
 		return
 	}
 
-	keys = bson.M{}
+	keys = make(bson.D, 0, len(index.Keys))
 
 	for _, v := range index.Keys {
-		if v[0] == '-' {
-			v = v[1:]
-			keys[v] = -1
-		} else {
-			keys[v] = 1
+		var key bson.E
+		if key, err = convertIndexKey(v); err != nil {
+			return
 		}
+		keys = append(keys, key)
 	}
 
 	iopts = &index.IndexOptions
 	return
 }
+
+// convert the key spec of index, the spec is one of
+//
+//	"name"            ascending
+//	"-name"           descending
+//	"name:text"       index type, text, 2dsphere, 2d or hashed
+//	"$**", "tags.$**" wildcard, "$**:text" indexes all string fields as text
+func convertIndexKey(spec string) (key bson.E, err error) {
+	if spec == "" || spec == "-" {
+		return key, ErrNoIndexKey
+	}
+	if i := strings.LastIndex(spec, ":"); i > 0 {
+		name, typ := spec[:i], spec[i+1:]
+		if !indexTypes[typ] {
+			return key, fmt.Errorf("%w `%s` of `%s`", ErrIndexKeyType, typ, spec)
+		}
+		return bson.E{Key: name, Value: typ}, nil
+	}
+	if spec[0] == '-' {
+		return bson.E{Key: spec[1:], Value: -1}, nil
+	}
+	return bson.E{Key: spec, Value: 1}, nil
+}
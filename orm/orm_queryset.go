@@ -66,6 +66,9 @@ type querySet struct {
 	orders     []string
 	distinct   bool
 	forupdate  bool
+	scope      int // soft delete scope
+	arrFilters []*Condition
+	strict     bool // error on unknown fields
 	orm        *orm
 	ctx        context.Context
 	forContext bool
@@ -193,11 +196,107 @@ func (o *querySet) Update(operator OperatorUpdate, values Params) (i int64, err
 	return o.orm.alias.DbBaser.UpdateMany(o, o.mi, o.cond, operator, values, o.orm.alias.TZ)
 }
 
+// execute the update builder on the first matched row.
+func (o *querySet) UpdateOne(u *Update) (*UpdateResult, error) {
+	return o.orm.alias.DbBaser.UpdateWith(o, o.mi, o.cond, u, true, o.orm.alias.TZ)
+}
+
+// execute the update builder on all matched rows.
+func (o *querySet) UpdateMany(u *Update) (*UpdateResult, error) {
+	return o.orm.alias.DbBaser.UpdateWith(o, o.mi, o.cond, u, false, o.orm.alias.TZ)
+}
+
+// execute update with aggregation pipeline stages, e.g.
+//
+//	qs.UpdatePipeline(bson.M{"$set": bson.M{"full_name": bson.M{"$concat": bson.A{"$first", " ", "$last"}}}})
+//
+// it needs MongoDB 4.2+.
+func (o *querySet) UpdatePipeline(stages ...interface{}) (int64, error) {
+	return o.orm.alias.DbBaser.UpdatePipeline(o, o.mi, o.cond, stages, o.orm.alias.TZ)
+}
+
 // execute delete
+// the documents of model with soft_delete field are marked as deleted unless Unscoped.
 func (o *querySet) Delete() (i int64, err error) {
+	if o.mi.fields.softDelete != nil && o.scope != scopeUnscoped {
+		return o.orm.alias.DbBaser.SoftDeleteMany(o, o.mi, o.cond, o.orm.alias.TZ, false)
+	}
 	return o.orm.alias.DbBaser.DeleteMany(o, o.mi, o.cond, o.orm.alias.TZ)
 }
 
+// execute delete, the soft deleted documents are matched too unless OnlyDeleted.
+func (o querySet) ForceDelete() (i int64, err error) {
+	if o.scope == scopeDefault {
+		o.scope = scopeWithDeleted
+	}
+	return o.orm.alias.DbBaser.DeleteMany(&o, o.mi, o.cond, o.orm.alias.TZ)
+}
+
+// restore the soft deleted documents.
+func (o *querySet) Restore() (i int64, err error) {
+	return o.orm.alias.DbBaser.SoftDeleteMany(o, o.mi, o.cond, o.orm.alias.TZ, true)
+}
+
+// set the arrayFilters of updates, which are used by the $[<identifier>] paths, e.g.
+//
+//	qs.ArrayFilters(orm.NewCondition().And("elem__qty__lt", 10)).
+//		Update(orm.MgoSet, orm.Params{"items.$[elem].status": "low"})
+func (o querySet) ArrayFilters(conds ...*Condition) QuerySeter {
+	o.arrFilters = append(o.arrFilters[:len(o.arrFilters):len(o.arrFilters)], conds...)
+	return &o
+}
+
+// query the soft deleted documents too, and Delete removes documents.
+func (o querySet) Unscoped() QuerySeter {
+	o.scope = scopeUnscoped
+	return &o
+}
+
+// query the soft deleted documents too.
+func (o querySet) WithDeleted() QuerySeter {
+	o.scope = scopeWithDeleted
+	return &o
+}
+
+// query the soft deleted documents only.
+func (o querySet) OnlyDeleted() QuerySeter {
+	o.scope = scopeOnlyDeleted
+	return &o
+}
+
+// return ErrUnknownField when the names in filters, orders, cols, Distinct or update keys
+// are not the fields of model, by default the unknown names are passed to mongo as they are.
+func (o querySet) Strict(strict bool) QuerySeter {
+	o.strict = strict
+	return &o
+}
+
+// find one row, update it and map the original (or updated) row to container.
+// only the first FindOneAndOptions is used.
+func (o *querySet) FindOneAndUpdate(container interface{}, operator OperatorUpdate, values Params, opts ...FindOneAndOptions) (err error) {
+	var fo FindOneAndOptions
+	if len(opts) > 0 {
+		fo = opts[0]
+	}
+	return o.orm.alias.DbBaser.FindOneAndUpdate(o, o.mi, o.cond, container, operator, values, fo, o.orm.alias.TZ)
+}
+
+// find one row, replace it with replacement and map the original (or new) row to container.
+// only the first FindOneAndOptions is used.
+func (o *querySet) FindOneAndReplace(container interface{}, replacement interface{}, opts ...FindOneAndOptions) (err error) {
+	var fo FindOneAndOptions
+	if len(opts) > 0 {
+		fo = opts[0]
+	}
+	return o.orm.alias.DbBaser.FindOneAndReplace(o, o.mi, o.cond, container, replacement, fo, o.orm.alias.TZ)
+}
+
+// find one row, delete it and map the deleted row to container.
+// cols means the columns when querying.
+func (o *querySet) FindOneAndDelete(container interface{}, cols ...string) (err error) {
+	return o.orm.alias.DbBaser.FindOneAndDelete(o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
+}
+
 // get indexview
 func (o *querySet) IndexView() (iv IndexViewer) {
 	return o.orm.alias.DbBaser.Indexes(o, o.mi, o.orm.alias.TZ)
@@ -206,7 +305,13 @@ func (o *querySet) IndexView() (iv IndexViewer) {
 // query all data and map to containers.
 // cols means the columns when querying.
 func (o *querySet) All(container interface{}, cols ...string) (err error) {
-	return o.orm.alias.DbBaser.Find(o, o.mi, o.cond, container, o.orm.alias.TZ, cols)
+	if err = o.orm.alias.DbBaser.Find(o, o.mi, o.cond, container, o.orm.alias.TZ, cols); err != nil {
+		return
+	}
+	if len(cols) == 0 {
+		o.orm.track(o.mi, container)
+	}
+	return callAfterRead(o.getContext(), o.orm, container)
 }
 
 // query one row data and map to containers.
@@ -217,8 +322,10 @@ func (o *querySet) One(container interface{}, cols ...string) (err error) {
 	if err != nil {
 		return err
 	}
-	return
-
+	if len(cols) == 0 {
+		o.orm.track(o.mi, container)
+	}
+	return callAfterRead(o.getContext(), o.orm, container)
 }
 
 func (o *querySet) Distinct(field string) (res []interface{}, err error) {
@@ -280,6 +387,17 @@ func (o querySet) WithContext(ctx context.Context) QuerySeter {
 	return &o
 }
 
+// get the context set by WithContext, default is the context of Ormer.
+func (o *querySet) getContext() context.Context {
+	if o.forContext {
+		return o.ctx
+	}
+	if o.orm != nil {
+		return o.orm.getContext()
+	}
+	return todo
+}
+
 // create new QuerySeter.
 func newQuerySet(orm *orm, mi *modelInfo) QuerySeter {
 	o := new(querySet)
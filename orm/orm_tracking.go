@@ -0,0 +1,214 @@
+package orm
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// DefaultTrackingSize the max snapshots kept by Ormer in tracking mode,
+// the least recently used snapshot is dropped when it is exceeded.
+var DefaultTrackingSize = 1000
+
+// the documents loaded by Ormer in tracking mode, keyed by model pointer.
+// it is created with Ormer and shared by its copies, all fields are guarded by mux.
+type snapshots struct {
+	mux     sync.Mutex
+	enabled bool
+	size    int
+	lru     *list.List // the keys of cache, the recently used first
+	cache   map[interface{}]*list.Element
+}
+
+// the entry of snapshots lru list
+type snapshot struct {
+	md   interface{}
+	data bson.Raw
+}
+
+// create the snapshots, tracking mode is disabled.
+func newSnapshots() *snapshots {
+	return &snapshots{
+		size:  DefaultTrackingSize,
+		lru:   list.New(),
+		cache: make(map[interface{}]*list.Element),
+	}
+}
+
+// enable or disable the tracking mode.
+// in tracking mode Read, QuerySeter.One and All snapshot the loaded models,
+// and Update without cols sends only the changed fields, nil pointers are $unset.
+// at most DefaultTrackingSize snapshots are kept, disable it clears all snapshots.
+func (o *orm) Tracking(enable bool) {
+	s := o.snaps
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.enabled = enable
+	if !enable {
+		s.lru.Init()
+		s.cache = make(map[interface{}]*list.Element)
+	}
+}
+
+// snapshot the models in container, container can be pointer of struct, []T or []*T.
+func (o *orm) track(mi *modelInfo, container interface{}) {
+	s := o.snaps
+	if s == nil {
+		return
+	}
+	typ := mi.addrField.Type()
+	snap := func(v reflect.Value) {
+		if !v.IsValid() || v.Type() != typ || v.IsNil() {
+			return
+		}
+		md := v.Interface()
+		data, err := bson.Marshal(md)
+		if err != nil {
+			return
+		}
+		s.mux.Lock()
+		s.put(md, data)
+		s.mux.Unlock()
+	}
+
+	s.mux.Lock()
+	enabled := s.enabled
+	s.mux.Unlock()
+	if !enabled {
+		return
+	}
+	val := reflect.ValueOf(container)
+	ind := reflect.Indirect(val)
+	if ind.Kind() != reflect.Slice && ind.Kind() != reflect.Array {
+		snap(val)
+		return
+	}
+	for i := 0; i < ind.Len(); i++ {
+		v := ind.Index(i)
+		if v.Kind() != reflect.Ptr {
+			if !v.CanAddr() {
+				continue
+			}
+			v = v.Addr()
+		}
+		snap(v)
+	}
+}
+
+// put the snapshot of model and drop the least recently used ones over size, mux must be held.
+func (s *snapshots) put(md interface{}, data bson.Raw) {
+	if !s.enabled {
+		return
+	}
+	if e, ok := s.cache[md]; ok {
+		e.Value.(*snapshot).data = data
+		s.lru.MoveToFront(e)
+		return
+	}
+	s.cache[md] = s.lru.PushFront(&snapshot{md: md, data: data})
+	for s.size > 0 && s.lru.Len() > s.size {
+		e := s.lru.Back()
+		s.lru.Remove(e)
+		delete(s.cache, e.Value.(*snapshot).md)
+	}
+}
+
+// remove the snapshot of model.
+func (o *orm) untrack(md interface{}) {
+	s := o.snaps
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	if e, ok := s.cache[md]; ok {
+		s.lru.Remove(e)
+		delete(s.cache, md)
+	}
+	s.mux.Unlock()
+}
+
+// get the snapshot of model.
+func (o *orm) snapshot(md interface{}) (data bson.Raw, ok bool) {
+	s := o.snaps
+	if s == nil {
+		return
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	e, ok := s.cache[md]
+	if !ok {
+		return
+	}
+	s.lru.MoveToFront(e)
+	return e.Value.(*snapshot).data, true
+}
+
+// compare the model with its snapshot,
+// get the changed columns and the columns become null or absent.
+// ok is false if the changes can not be mapped to fields.
+func diffSnapshot(mi *modelInfo, old bson.Raw, md interface{}) (cols []string, unsets []string, ok bool) {
+	data, err := bson.Marshal(md)
+	if err != nil {
+		return
+	}
+	elems, err := bson.Raw(data).Elements()
+	if err != nil {
+		return
+	}
+	olds, err := old.Elements()
+	if err != nil {
+		return
+	}
+
+	column := func(key string) (string, bool) {
+		if fi := mi.fields.GetByColumn(key); fi != nil {
+			return fi.column, true
+		}
+		return "", false
+	}
+
+	seen := make(map[string]bool, len(elems))
+	for _, e := range elems {
+		key := e.Key()
+		seen[key] = true
+		v := e.Value()
+		ov, err := old.LookupErr(key)
+		exist := err == nil && ov.Type != bsontype.Null
+		if v.Type == bsontype.Null {
+			if !exist {
+				continue
+			}
+			col, found := column(key)
+			if !found {
+				return nil, nil, false
+			}
+			unsets = append(unsets, col)
+			continue
+		}
+		if exist && v.Equal(ov) {
+			continue
+		}
+		col, found := column(key)
+		if !found {
+			return nil, nil, false
+		}
+		cols = append(cols, col)
+	}
+
+	// absent by omitempty
+	for _, e := range olds {
+		key := e.Key()
+		if seen[key] || e.Value().Type == bsontype.Null {
+			continue
+		}
+		col, found := column(key)
+		if !found {
+			return nil, nil, false
+		}
+		unsets = append(unsets, col)
+	}
+	return cols, unsets, true
+}
@@ -0,0 +1,179 @@
+package orm
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type updateValue struct {
+	operator OperatorUpdate
+	field    string
+	value    interface{}
+	md       interface{} // model struct of SetModel
+	cols     []string
+}
+
+// Update struct.
+// work for the update document combines multiple operators, e.g.
+//
+//	u := orm.NewUpdate().Set("Name", "siot").Inc("Count", 1).Unset("Temp")
+//	res, err := o.QueryTable("user").Filter("Id", id).UpdateOne(u)
+//
+// the field names are mapped to columns, nested path like "Profile.Name" is mapped by the bson tags.
+type Update struct {
+	params []updateValue
+	upsert bool
+}
+
+// UpdateResult the result of QuerySeter.UpdateOne and UpdateMany
+type UpdateResult struct {
+	MatchedCount  int64
+	ModifiedCount int64
+	UpsertedCount int64
+	UpsertedID    interface{}
+}
+
+// PushEach the $each of $push with modifiers, e.g.
+//
+//	Params{"scores": orm.Each(90, 92).Sort(-1).Slice(-5)}
+type PushEach struct {
+	each     []interface{}
+	slice    *int
+	sort     interface{}
+	position *int
+}
+
+// Each return the $each of values
+func Each(values ...interface{}) *PushEach {
+	return &PushEach{each: values}
+}
+
+// Slice add $slice modifier, keep the first n elements, or the last if n is negative
+func (p PushEach) Slice(n int) *PushEach {
+	p.slice = &n
+	return &p
+}
+
+// Sort add $sort modifier, 1, -1 or the document of sub fields like bson.M{"score": -1}
+func (p PushEach) Sort(sort interface{}) *PushEach {
+	p.sort = sort
+	return &p
+}
+
+// Position add $position modifier, insert at the index n
+func (p PushEach) Position(n int) *PushEach {
+	p.position = &n
+	return &p
+}
+
+// MarshalBSON implement bson.Marshaler
+func (p *PushEach) MarshalBSON() ([]byte, error) {
+	each := p.each
+	if each == nil {
+		each = []interface{}{}
+	}
+	doc := bson.D{{Key: "$each", Value: each}}
+	if p.position != nil {
+		doc = append(doc, bson.E{Key: "$position", Value: *p.position})
+	}
+	if p.sort != nil {
+		doc = append(doc, bson.E{Key: "$sort", Value: p.sort})
+	}
+	if p.slice != nil {
+		doc = append(doc, bson.E{Key: "$slice", Value: *p.slice})
+	}
+	return bson.Marshal(doc)
+}
+
+// NewUpdate return new update struct
+func NewUpdate() *Update {
+	u := &Update{}
+	return u
+}
+
+func (u Update) add(operator OperatorUpdate, field string, value interface{}) *Update {
+	if field == "" {
+		panic(fmt.Errorf("<Update.%s> field cannot empty", strings.TrimPrefix(string(operator), "$")))
+	}
+	u.params = append(u.params[:len(u.params):len(u.params)], updateValue{operator: operator, field: field, value: value})
+	return &u
+}
+
+// Set add $set field
+func (u Update) Set(field string, value interface{}) *Update {
+	return u.add(MgoSet, field, value)
+}
+
+// SetModel add the fields of model struct to $set, cols limit the fields, default is all except pk.
+func (u Update) SetModel(md interface{}, cols ...string) *Update {
+	if md == nil {
+		panic(fmt.Errorf("<Update.SetModel> model cannot nil"))
+	}
+	u.params = append(u.params[:len(u.params):len(u.params)], updateValue{operator: MgoSet, md: md, cols: cols})
+	return &u
+}
+
+// SetOnInsert add $setOnInsert field, it works for upsert only
+func (u Update) SetOnInsert(field string, value interface{}) *Update {
+	return u.add(MgoSetOnInsert, field, value)
+}
+
+// Inc add $inc field
+func (u Update) Inc(field string, value interface{}) *Update {
+	return u.add(MgoInc, field, value)
+}
+
+// Push add $push field
+func (u Update) Push(field string, value interface{}) *Update {
+	return u.add(MgoPush, field, value)
+}
+
+// AddToSet add $addToSet field
+func (u Update) AddToSet(field string, value interface{}) *Update {
+	return u.add(MgoAddToSet, field, value)
+}
+
+// Pull add $pull field
+func (u Update) Pull(field string, value interface{}) *Update {
+	return u.add(MgoPull, field, value)
+}
+
+// Unset add $unset fields
+func (u Update) Unset(fields ...string) *Update {
+	p := &u
+	for _, field := range fields {
+		p = p.add(MgoUnSet, field, "")
+	}
+	return p
+}
+
+// Upsert insert a document when no document matches
+func (u Update) Upsert(upsert bool) *Update {
+	u.upsert = upsert
+	return &u
+}
+
+// IsEmpty check the update has not any field
+func (u *Update) IsEmpty() bool {
+	return len(u.params) == 0
+}
+
+// get the update document without the model fields.
+func (u *Update) toBSON(cm *columnMapper) bson.M {
+	update := bson.M{}
+	for _, p := range u.params {
+		if p.md != nil {
+			continue
+		}
+		m, ok := update[string(p.operator)].(bson.M)
+		if !ok {
+			m = bson.M{}
+			update[string(p.operator)] = m
+		}
+		m[cm.column(p.field)] = p.value
+	}
+	setColValues(update)
+	return update
+}
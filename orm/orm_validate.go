@@ -0,0 +1,211 @@
+package orm
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator is called after the tag rules when the model is validated before writes,
+// it is used for the cross-field rules.
+// return a *ValidationError to report the failing fields, other errors are reported as rule `validate`.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError one failing rule of a field
+type FieldError struct {
+	Field   string      // struct field name, empty for the errors of Validator
+	Column  string      // bson column
+	Rule    string      // failing rule, e.g. required, max(10)
+	Value   interface{} // the field value
+	Message string
+}
+
+// Error implement error
+func (e *FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationError returned by Insert, Update, Replace and upserts when the model is invalid,
+// it lists every failing field.
+type ValidationError struct {
+	Model  string
+	Errors []*FieldError
+}
+
+// Add append a field error, it can be used in Validator.
+func (e *ValidationError) Add(field, rule, message string) {
+	e.Errors = append(e.Errors, &FieldError{Field: field, Rule: rule, Message: message})
+}
+
+// Error implement error
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, fe := range e.Errors {
+		msgs = append(msgs, fe.Error())
+	}
+	return fmt.Sprintf("<Ormer> model `%s` validation failed: %s", e.Model, strings.Join(msgs, "; "))
+}
+
+// validate the model fields by tags and the Validator of model.
+// cols limit the fields validated, default is all.
+func validateModel(mi *modelInfo, ind reflect.Value, cols []string) error {
+	ve := &ValidationError{Model: mi.fullName}
+	fis := mi.fields.fieldsDB
+	if len(cols) > 0 {
+		fis = make([]*fieldInfo, 0, len(cols))
+		for _, col := range cols {
+			if fi, ok := mi.fields.GetByAny(col); ok {
+				fis = append(fis, fi)
+			}
+		}
+	}
+	for _, fi := range fis {
+		validateField(ve, fi, ind.FieldByIndex(fi.fieldIndex))
+	}
+
+	if v, ok := ind.Addr().Interface().(Validator); ok {
+		if err := v.Validate(); err != nil {
+			if e, ok := err.(*ValidationError); ok {
+				ve.Errors = append(ve.Errors, e.Errors...)
+			} else {
+				ve.Errors = append(ve.Errors, &FieldError{Rule: "validate", Message: err.Error()})
+			}
+		}
+	}
+
+	if len(ve.Errors) > 0 {
+		return ve
+	}
+	return nil
+}
+
+// validate the update values of known fields.
+// only $set and $setOnInsert values are validated, the absent fields are not checked.
+func validateParams(mi *modelInfo, operator OperatorUpdate, params Params) error {
+	if operator != MgoSet && operator != MgoSetOnInsert {
+		return nil
+	}
+	ve := &ValidationError{Model: mi.fullName}
+	for k, v := range params {
+		fi, ok := mi.fields.GetByAny(k)
+		if !ok || v == nil {
+			continue
+		}
+		if _, ok := v.(colValue); ok {
+			continue
+		}
+		validateField(ve, fi, reflect.ValueOf(v))
+	}
+	if len(ve.Errors) > 0 {
+		return ve
+	}
+	return nil
+}
+
+// validate one field value, the errors are appended to ve.
+func validateField(ve *ValidationError, fi *fieldInfo, field reflect.Value) {
+	add := func(rule, format string, args ...interface{}) {
+		ve.Errors = append(ve.Errors, &FieldError{
+			Field:   fi.name,
+			Column:  fi.column,
+			Rule:    rule,
+			Value:   field.Interface(),
+			Message: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if isZeroField(field) {
+		if fi.required {
+			add("required", "is required")
+		}
+		// the empty value of null field is not checked
+		if fi.null || field.Kind() == reflect.Ptr || field.Kind() == reflect.Interface {
+			return
+		}
+	}
+	val := reflect.Indirect(field)
+	if val.Kind() == reflect.Interface {
+		val = val.Elem()
+	}
+	if !val.IsValid() {
+		return
+	}
+
+	switch fi.fieldType {
+	case TypeVarCharField, TypeCharField, TypeJSONField, TypeJsonbField:
+		if !fi.toText && fi.size > 0 && val.Kind() == reflect.String {
+			if n := utf8.RuneCountInString(val.String()); n > fi.size {
+				add(fmt.Sprintf("size(%d)", fi.size), "length %d is longer than %d", n, fi.size)
+			}
+		}
+	case TypeDecimalField:
+		if val.Kind() == reflect.Float32 || val.Kind() == reflect.Float64 {
+			s := strconv.FormatFloat(val.Float(), 'f', -1, 64)
+			s = strings.TrimPrefix(s, "-")
+			n, d := len(s), 0
+			if i := strings.Index(s, "."); i >= 0 {
+				n, d = i, len(s)-i-1
+			}
+			if d > fi.decimals || n > fi.digits-fi.decimals {
+				add(fmt.Sprintf("digits(%d);decimals(%d)", fi.digits, fi.decimals), "%s is out of %d digits with %d decimals", s, fi.digits, fi.decimals)
+			}
+		}
+	}
+
+	if fi.hasMin || fi.hasMax {
+		if n, isLen, ok := validateNumber(val); ok {
+			what := "value"
+			if isLen {
+				what = "length"
+			}
+			if fi.hasMin && n < fi.min {
+				add("min("+ToStr(fi.min)+")", "%s %v is less than %v", what, n, fi.min)
+			}
+			if fi.hasMax && n > fi.max {
+				add("max("+ToStr(fi.max)+")", "%s %v is greater than %v", what, n, fi.max)
+			}
+		}
+	}
+
+	if len(fi.enum) > 0 {
+		s := ToStr(val.Interface())
+		found := false
+		for _, e := range fi.enum {
+			if e == s {
+				found = true
+				break
+			}
+		}
+		if !found {
+			add("enum("+strings.Join(fi.enum, ",")+")", "%s is not one of %s", s, strings.Join(fi.enum, ", "))
+		}
+	}
+
+	if fi.regex != nil && val.Kind() == reflect.String && !fi.regex.MatchString(val.String()) {
+		add("regex("+fi.regex.String()+")", "%q does not match %s", val.String(), fi.regex.String())
+	}
+}
+
+// get the number compared by min and max, the length is used for string, slice and map.
+func validateNumber(val reflect.Value) (n float64, isLen bool, ok bool) {
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(val.Int()), false, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(val.Uint()), false, true
+	case reflect.Float32, reflect.Float64:
+		return val.Float(), false, true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(val.String())), true, true
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return float64(val.Len()), true, true
+	}
+	return
+}
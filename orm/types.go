@@ -15,8 +15,12 @@
 package orm
 
 import (
+	"context"
 	"reflect"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // Fielder define field info
@@ -32,17 +36,59 @@ type Ormer interface {
 	Read(md interface{}, cols ...string) error
 	ReadOrCreate(md interface{}, col1 string, cols ...string) (bool, interface{}, error)
 	Insert(interface{}) (interface{}, error)
-	InsertMulti(mds interface{}) (interface{}, error)
+	InsertMulti(mds interface{}, opts ...InsertMultiOptions) (*InsertMultiResult, error)
 	// InsertOrUpdate(md interface{}, colConflitAndArgs ...string) (int64, error)
 	Update(md interface{}, cols ...string) (interface{}, error)
+	UpdateNonZero(md interface{}) (interface{}, error)
+	UpdatePipeline(md interface{}, stages ...interface{}) (int64, error)
+	Replace(md interface{}, opts ...*options.ReplaceOptions) (interface{}, error)
 	Delete(md interface{}, cols ...string) (interface{}, error)
+	ForceDelete(md interface{}, cols ...string) (interface{}, error)
+	Restore(md interface{}, cols ...string) (interface{}, error)
 
 	QueryTable(ptrStructOrTableName interface{}) QuerySeter
+	Bulk(ptrStructOrTableName interface{}) BulkWriter
 
 	Begin() error
 	Commit() error
 	Rollback() error
 	Using(name string) error
+	Tracking(enable bool)
+	WithContext(ctx context.Context) Ormer
+}
+
+// InsertMultiOptions define the options of Ormer.InsertMulti
+type InsertMultiOptions struct {
+	ChunkSize int  // documents sent in one InsertMany, default is DefaultInsertChunkSize
+	Unordered bool // keep inserting the rest documents after a failure
+}
+
+// InsertFailure the failure of one document in Ormer.InsertMulti
+type InsertFailure struct {
+	Index int   // index in the given slice
+	Code  int   // mongodb error code
+	Err   error // the write error
+}
+
+// IsDuplicateKey check the document failed with duplicate key error
+func (f InsertFailure) IsDuplicateKey() bool {
+	return f.Code == 11000 || f.Code == 11001 || f.Code == 12582
+}
+
+// IsValidation check the document failed with document validation error,
+// either the ValidationError of model or the collection validator.
+func (f InsertFailure) IsValidation() bool {
+	if _, ok := f.Err.(*ValidationError); ok {
+		return true
+	}
+	return f.Code == 121
+}
+
+// InsertMultiResult the result of Ormer.InsertMulti
+type InsertMultiResult struct {
+	InsertedCount int64
+	Ids           []interface{} // ids in the order of given slice, nil if not inserted
+	Failed        []InsertFailure
 }
 
 type QuerySeter interface {
@@ -59,7 +105,20 @@ type QuerySeter interface {
 	Count() (int64, error)
 	Exist() bool
 	Update(OperatorUpdate, Params) (int64, error)
+	UpdateOne(*Update) (*UpdateResult, error)
+	UpdateMany(*Update) (*UpdateResult, error)
+	UpdatePipeline(stages ...interface{}) (int64, error)
 	Delete() (int64, error)
+	ForceDelete() (int64, error)
+	Restore() (int64, error)
+	ArrayFilters(conds ...*Condition) QuerySeter
+	Unscoped() QuerySeter
+	WithDeleted() QuerySeter
+	OnlyDeleted() QuerySeter
+	Strict(strict bool) QuerySeter
+	FindOneAndUpdate(interface{}, OperatorUpdate, Params, ...FindOneAndOptions) error
+	FindOneAndReplace(interface{}, interface{}, ...FindOneAndOptions) error
+	FindOneAndDelete(interface{}, ...string) error
 	All(interface{}, ...string) error
 	One(interface{}, ...string) error
 	Distinct(string) ([]interface{}, error)
@@ -72,6 +131,25 @@ type QuerySeter interface {
 	IndexView() IndexViewer
 }
 
+// FindOneAndOptions define the options of QuerySeter.FindOneAndUpdate and FindOneAndReplace
+type FindOneAndOptions struct {
+	Cols      []string // the columns to return, default is all
+	Upsert    bool     // insert a new document when no document matches
+	ReturnNew bool     // return the document after modification instead of before
+}
+
+type BulkWriter interface {
+	InsertOne(md interface{}) BulkWriter
+	UpdateOne(cond *Condition, operator OperatorUpdate, values Params, upsert ...bool) BulkWriter
+	UpdateMany(cond *Condition, operator OperatorUpdate, values Params, upsert ...bool) BulkWriter
+	ReplaceOne(md interface{}, upsert ...bool) BulkWriter
+	DeleteOne(cond *Condition) BulkWriter
+	DeleteMany(cond *Condition) BulkWriter
+	Ordered(bool) BulkWriter
+	Len() int
+	Exec() (*BulkResult, error)
+}
+
 type IndexViewer interface {
 	List() (interface{}, error)
 	CreateOne(Index, ...time.Duration) (string, error)
@@ -91,9 +169,14 @@ type dbQuerier interface {
 type dbBaser interface {
 	Read(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location, []string) error
 	InsertOne(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location) (interface{}, error)
-	InsertMany(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location) (interface{}, error)
+	InsertMany(dbQuerier, *modelInfo, []reflect.Value, InsertMultiOptions, *time.Location) (*InsertMultiResult, error)
 	UpdateOne(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location, []string) (interface{}, error)
+	UpdateChanged(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location, []string, []string, bool) (interface{}, error)
+	UpdatePipelineOne(dbQuerier, *modelInfo, reflect.Value, *time.Location, []interface{}) (int64, error)
+	ReplaceOne(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location, []*options.ReplaceOptions) (interface{}, error)
 	DeleteOne(dbQuerier, *modelInfo, reflect.Value, interface{}, *time.Location, []string) (interface{}, error)
+	SoftDeleteOne(dbQuerier, *modelInfo, reflect.Value, *time.Location, []string, bool) (interface{}, error)
+	BulkWrite(dbQuerier, *modelInfo, []mongo.WriteModel, bool, *time.Location, []string, []interface{}) (*BulkResult, error)
 
 	FindOne(*querySet, *modelInfo, *Condition, interface{}, *time.Location, []string) error
 	Distinct(*querySet, *modelInfo, *Condition, *time.Location, string) ([]interface{}, error)
@@ -101,6 +184,12 @@ type dbBaser interface {
 	Count(*querySet, *modelInfo, *Condition, *time.Location) (int64, error)
 	UpdateMany(*querySet, *modelInfo, *Condition, OperatorUpdate, Params, *time.Location) (int64, error)
 	DeleteMany(*querySet, *modelInfo, *Condition, *time.Location) (int64, error)
+	SoftDeleteMany(*querySet, *modelInfo, *Condition, *time.Location, bool) (int64, error)
+	UpdateWith(*querySet, *modelInfo, *Condition, *Update, bool, *time.Location) (*UpdateResult, error)
+	UpdatePipeline(*querySet, *modelInfo, *Condition, []interface{}, *time.Location) (int64, error)
+	FindOneAndUpdate(*querySet, *modelInfo, *Condition, interface{}, OperatorUpdate, Params, FindOneAndOptions, *time.Location) error
+	FindOneAndReplace(*querySet, *modelInfo, *Condition, interface{}, interface{}, FindOneAndOptions, *time.Location) error
+	FindOneAndDelete(*querySet, *modelInfo, *Condition, interface{}, *time.Location, []string) error
 	Indexes(*querySet, *modelInfo, *time.Location) IndexViewer
 	TimeFromDB(*time.Time, *time.Location)
 	TimeToDB(*time.Time, *time.Location)